@@ -0,0 +1,227 @@
+package glint
+
+import (
+	"context"
+
+	"github.com/mitchellh/go-glint/flex"
+	"github.com/mitchellh/go-glint/internal/layout"
+)
+
+// Box draws a border around the inner components. The border is drawn
+// with BorderSingle by default. The builder methods can be used to change
+// the border, set a title, style the border, and disable edges.
+//
+// The border takes up space in the layout, so the inner components are
+// laid out within the border. Use Layout within the box to set padding
+// or other layout properties for the inner components, or wrap the box in
+// a Layout to set its size or position.
+func Box(inner ...Component) *BoxComponent {
+	return &BoxComponent{
+		inner:  inner,
+		border: BorderSingle,
+		edges:  [4]bool{true, true, true, true},
+	}
+}
+
+// BoxComponent is a component that draws a border. See Box.
+type BoxComponent struct {
+	inner  []Component
+	border BoxBorder
+	title  string
+	opts   []StyleOption
+
+	// edges are the enabled edges in the order top, right, bottom, left.
+	edges [4]bool
+}
+
+// BoxBorder is the set of characters used to draw the border of a Box.
+// Custom borders can be created by setting each character.
+type BoxBorder struct {
+	Top, Right, Bottom, Left                   rune
+	TopLeft, TopRight, BottomRight, BottomLeft rune
+}
+
+var (
+	// BorderSingle draws a border with single lines.
+	BorderSingle = BoxBorder{
+		Top: '─', Right: '│', Bottom: '─', Left: '│',
+		TopLeft: '┌', TopRight: '┐', BottomRight: '┘', BottomLeft: '└',
+	}
+
+	// BorderDouble draws a border with double lines.
+	BorderDouble = BoxBorder{
+		Top: '═', Right: '║', Bottom: '═', Left: '║',
+		TopLeft: '╔', TopRight: '╗', BottomRight: '╝', BottomLeft: '╚',
+	}
+
+	// BorderRounded draws a border with single lines and rounded corners.
+	BorderRounded = BoxBorder{
+		Top: '─', Right: '│', Bottom: '─', Left: '│',
+		TopLeft: '╭', TopRight: '╮', BottomRight: '╯', BottomLeft: '╰',
+	}
+
+	// BorderHeavy draws a border with thick lines.
+	BorderHeavy = BoxBorder{
+		Top: '━', Right: '┃', Bottom: '━', Left: '┃',
+		TopLeft: '┏', TopRight: '┓', BottomRight: '┛', BottomLeft: '┗',
+	}
+
+	// BorderASCII draws a border using only ASCII characters. This is
+	// useful for terminals that can't draw box-drawing characters.
+	BorderASCII = BoxBorder{
+		Top: '-', Right: '|', Bottom: '-', Left: '|',
+		TopLeft: '+', TopRight: '+', BottomRight: '+', BottomLeft: '+',
+	}
+)
+
+// Border sets the characters used to draw the border, such as BorderDouble.
+func (c *BoxComponent) Border(b BoxBorder) *BoxComponent {
+	c.border = b
+	return c
+}
+
+// Title sets a title that is drawn within the top edge of the border. The
+// title is truncated if it doesn't fit. The title is not drawn if the top
+// edge is disabled.
+func (c *BoxComponent) Title(title string) *BoxComponent {
+	c.title = title
+	return c
+}
+
+// Style sets the style of the border and title. This is applied on top
+// of any style the box is nested within using the Style component. The
+// style of the inner components is unaffected.
+func (c *BoxComponent) Style(opts ...StyleOption) *BoxComponent {
+	c.opts = opts
+	return c
+}
+
+// BorderTop enables or disables the top edge of the border. All edges are
+// enabled by default. A disabled edge takes up no space.
+func (c *BoxComponent) BorderTop(enabled bool) *BoxComponent {
+	c.edges[0] = enabled
+	return c
+}
+
+// BorderRight enables or disables the right edge of the border.
+func (c *BoxComponent) BorderRight(enabled bool) *BoxComponent {
+	c.edges[1] = enabled
+	return c
+}
+
+// BorderBottom enables or disables the bottom edge of the border.
+func (c *BoxComponent) BorderBottom(enabled bool) *BoxComponent {
+	c.edges[2] = enabled
+	return c
+}
+
+// BorderLeft enables or disables the left edge of the border.
+func (c *BoxComponent) BorderLeft(enabled bool) *BoxComponent {
+	c.edges[3] = enabled
+	return c
+}
+
+// Component implementation
+func (c *BoxComponent) Body(context.Context) Component {
+	return Fragment(c.inner...)
+}
+
+// Dirty implements ComponentDirtier. Like LayoutComponent, changes to the
+// box after it has been rendered are not detected.
+func (c *BoxComponent) Dirty(context.Context) bool {
+	return false
+}
+
+// componentLayout internal implementation.
+func (c *BoxComponent) Layout() *layout.Builder {
+	return (&layout.Builder{}).Raw(func(n *flex.Node) {
+		for i, edge := range boxEdges {
+			if c.edges[i] {
+				n.StyleSetBorder(edge, 1)
+			}
+		}
+	})
+}
+
+// borderStyle returns the style of the border given the context the box
+// is rendered in.
+func (c *BoxComponent) borderStyle(ctx context.Context) TextStyle {
+	result := styleFromContext(ctx)
+	for _, opt := range c.opts {
+		opt(&result)
+	}
+
+	return result
+}
+
+// drawBorder calls f for each cell of the border of a box with the given
+// size. The position is relative to the top-left corner of the box.
+func (c *BoxComponent) drawBorder(width, height int, f func(x, y int, r rune)) {
+	if width <= 0 || height <= 0 {
+		return
+	}
+
+	top, right, bottom, left := c.edges[0], c.edges[1], c.edges[2], c.edges[3]
+	b := c.border
+
+	// Draw the sides between the top and bottom edges.
+	startY, endY := 0, height
+	if top {
+		startY++
+	}
+	if bottom {
+		endY--
+	}
+	for y := startY; y < endY; y++ {
+		if left {
+			f(0, y, b.Left)
+		}
+		if right {
+			f(width-1, y, b.Right)
+		}
+	}
+
+	// row draws the top or bottom edge. Corners are only drawn if the
+	// side next to them is drawn, otherwise the edge continues.
+	row := func(y int, line, leftCorner, rightCorner rune) {
+		for x := 0; x < width; x++ {
+			r := line
+			if x == 0 && left {
+				r = leftCorner
+			} else if x == width-1 && right {
+				r = rightCorner
+			}
+
+			f(x, y, r)
+		}
+	}
+
+	if top {
+		row(0, b.Top, b.TopLeft, b.TopRight)
+
+		// Draw the title over the top edge between the corners.
+		if c.title != "" {
+			start, end := 0, width
+			if left {
+				start++
+			}
+			if right {
+				end--
+			}
+
+			title := []rune(" " + c.title + " ")
+			if len(title) > end-start {
+				title = title[:end-start]
+			}
+			for i, r := range title {
+				f(start+i, 0, r)
+			}
+		}
+	}
+	if bottom && (height > 1 || !top) {
+		row(height-1, b.Bottom, b.BottomLeft, b.BottomRight)
+	}
+}
+
+// boxEdges are the flex edges in the same order as BoxComponent.edges.
+var boxEdges = [4]flex.Edge{flex.EdgeTop, flex.EdgeRight, flex.EdgeBottom, flex.EdgeLeft}
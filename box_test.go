@@ -0,0 +1,128 @@
+package glint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBox(t *testing.T) {
+	cases := []struct {
+		Name     string
+		C        Component
+		Expected string
+	}{
+		{
+			"single",
+			Layout(Box(Text("hello"))).Row(),
+			"┌─────┐\n│hello│\n└─────┘",
+		},
+		{
+			"double",
+			Layout(Box(Text("hi")).Border(BorderDouble)).Row(),
+			"╔══╗\n║hi║\n╚══╝",
+		},
+		{
+			"rounded",
+			Layout(Box(Text("hi")).Border(BorderRounded)).Row(),
+			"╭──╮\n│hi│\n╰──╯",
+		},
+		{
+			"heavy",
+			Layout(Box(Text("hi")).Border(BorderHeavy)).Row(),
+			"┏━━┓\n┃hi┃\n┗━━┛",
+		},
+		{
+			"ascii",
+			Layout(Box(Text("hi")).Border(BorderASCII)).Row(),
+			"+--+\n|hi|\n+--+",
+		},
+		{
+			"full width",
+			Layout(Box(Text("hi"))).Width(10),
+			"┌" + strings.Repeat("─", 8) + "┐\n│hi      │\n└" + strings.Repeat("─", 8) + "┘",
+		},
+		{
+			"title",
+			Layout(Box(Text("hello world")).Title("Status")).Row(),
+			"┌ Status ───┐\n│hello world│\n└───────────┘",
+		},
+		{
+			"title truncated",
+			Layout(Box(Text("abc")).Title("Status")).Row(),
+			"┌ St┐\n│abc│\n└───┘",
+		},
+		{
+			"no top",
+			Layout(Box(Text("hi")).BorderTop(false)).Row(),
+			"│hi│\n└──┘",
+		},
+		{
+			"no sides",
+			Layout(Box(Text("hi")).BorderLeft(false).BorderRight(false)).Row(),
+			"──\nhi\n──",
+		},
+		{
+			"only bottom",
+			Layout(Box(Text("hi")).BorderTop(false).BorderLeft(false).BorderRight(false)).Row(),
+			"hi\n──",
+		},
+		{
+			"padding inside",
+			Layout(Box(Layout(Text("hi")).PaddingLeft(1).PaddingRight(1))).Row(),
+			"┌────┐\n│ hi │\n└────┘",
+		},
+		{
+			"side by side",
+			Layout(Box(Text("a\nb")), Box(Text("c"))).Row(),
+			"┌─┐┌─┐\n│a││c│\n│b││ │\n└─┘└─┘",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			require.Equal(t, tt.Expected, TestRender(t, tt.C))
+		})
+	}
+}
+
+func TestBox_style(t *testing.T) {
+	require := require.New(t)
+
+	r := &goldenRenderer{}
+	d := New()
+	d.SetRenderer(r)
+	d.Append(Layout(
+		Style(Box(Text("a")).Style(Bold()), Color("red")),
+		Box(Style(Text("b"), Color("green"))).Style(Color("blue")),
+	).Row())
+	d.RenderFrame()
+
+	// The border style is layered on the style it is nested within, and
+	// the border style doesn't apply to the inner components.
+	require.Equal(strings.Join([]string{
+		"⟨fg=red bold⟩┌─┐⟨/⟩⟨fg=blue⟩┌─┐⟨/⟩",
+		"⟨fg=red bold⟩│⟨/⟩⟨fg=red⟩a⟨/⟩⟨fg=red bold⟩│⟨/⟩⟨fg=blue⟩│⟨/⟩⟨fg=green⟩b⟨/⟩⟨fg=blue⟩│⟨/⟩",
+		"⟨fg=red bold⟩└─┘⟨/⟩⟨fg=blue⟩└─┘⟨/⟩",
+	}, "\n"), r.Builder.String())
+}
+
+func TestBox_html(t *testing.T) {
+	require := require.New(t)
+
+	r := &HTMLRenderer{Width: 4}
+	d := New()
+	d.SetRenderer(r)
+	d.Append(Box(Text("hi")).Border(BorderASCII).Style(Color("red")))
+	d.RenderFrame()
+
+	require.Equal(`<div class="glint" style="position:relative;width:4ch;height:3.6em;font-family:monospace;white-space:pre;line-height:1.2em">
+<div style="position:absolute;left:0ch;top:0em;width:4ch;height:1.2em;overflow:hidden"><span style="color:#aa0000">+--+</span></div>
+<div style="position:absolute;left:0ch;top:1.2em;width:1ch;height:1.2em;overflow:hidden"><span style="color:#aa0000">|</span></div>
+<div style="position:absolute;left:3ch;top:1.2em;width:1ch;height:1.2em;overflow:hidden"><span style="color:#aa0000">|</span></div>
+<div style="position:absolute;left:0ch;top:2.4em;width:4ch;height:1.2em;overflow:hidden"><span style="color:#aa0000">+--+</span></div>
+<div style="position:absolute;left:1ch;top:1.2em;width:2ch;height:1.2em;overflow:hidden">hi</div>
+</div>
+`, r.Builder.String())
+}
@@ -0,0 +1,246 @@
+package glint
+
+import (
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/go-glint/flex"
+)
+
+// canvas is a grid of cells that a laid out tree is drawn onto. Each node
+// is drawn at the absolute position computed by the layout, so nodes that
+// are side by side, such as multi-line children of a row, are drawn as
+// columns.
+type canvas struct {
+	rows [][]canvasCell
+}
+
+// canvasCell is a single cell of a canvas. Cells that were never drawn
+// are not set and are written as spaces, or not at all if they're at the
+// end of a row.
+type canvasCell struct {
+	R     rune
+	Style TextStyle
+	Set   bool
+}
+
+// newCanvas returns a canvas with the height of the given root.
+func newCanvas(root *flex.Node) *canvas {
+	return &canvas{rows: make([][]canvasCell, int(root.LayoutGetHeight()))}
+}
+
+// drawTree draws the children of parent. x and y are the absolute position
+// of parent. Children are drawn in paint order (see paintOrder) so later
+// children are drawn over earlier children.
+func (c *canvas) drawTree(parent *flex.Node, x, y int) {
+	for _, child := range paintOrder(parent) {
+		// Ignore children with a zero height
+		if child.LayoutGetHeight() == 0 {
+			continue
+		}
+
+		childX := x + int(child.LayoutGetLeft())
+		childY := y + int(child.LayoutGetTop())
+
+		// If we're not a text node then we're a container and we
+		// draw our children.
+		ctx, ok := child.Context.(*TextNodeContext)
+		if !ok {
+			// Absolutely positioned containers are overlays, so they
+			// hide anything that was drawn beneath them.
+			if child.Style.PositionType == flex.PositionTypeAbsolute {
+				c.clear(childX, childY, int(child.LayoutGetWidth()), int(child.LayoutGetHeight()))
+			}
+
+			c.drawBorder(child, childX, childY)
+			c.drawTree(child, childX, childY)
+			c.drawRightSpacing(child, childX, childY)
+			continue
+		}
+
+		c.drawText(child, ctx, childX, childY)
+	}
+}
+
+// drawText draws the text of a text node at the given position. The text
+// is clipped to the size of the node.
+func (c *canvas) drawText(node *flex.Node, ctx *TextNodeContext, x, y int) {
+	width := int(node.LayoutGetWidth())
+	height := int(node.LayoutGetHeight())
+	for i, line := range strings.Split(ctx.Text, "\n") {
+		if i >= height {
+			break
+		}
+
+		col := 0
+		for _, r := range line {
+			if col >= width {
+				break
+			}
+
+			c.set(x+col, y+i, canvasCell{R: r, Style: ctx.Style, Set: true})
+			col++
+		}
+	}
+}
+
+// drawBorder draws the border of the node if it is a box. See Box.
+func (c *canvas) drawBorder(node *flex.Node, x, y int) {
+	ctx, ok := node.Context.(*parentContext)
+	if !ok {
+		return
+	}
+
+	box, ok := ctx.C.(*BoxComponent)
+	if !ok {
+		return
+	}
+
+	box.drawBorder(int(node.LayoutGetWidth()), int(node.LayoutGetHeight()), func(bx, by int, r rune) {
+		c.set(x+bx, y+by, canvasCell{R: r, Style: ctx.style, Set: true})
+	})
+}
+
+// drawRightSpacing draws the right padding and margin of a container as
+// spaces after the content on each of its rows. The spacing is only
+// drawn on rows that have content within the node.
+func (c *canvas) drawRightSpacing(node *flex.Node, x, y int) {
+	spacing := int(node.LayoutGetPadding(flex.EdgeRight) + node.LayoutGetMargin(flex.EdgeRight))
+	if spacing <= 0 {
+		return
+	}
+
+	left := x - int(node.LayoutGetMargin(flex.EdgeLeft))
+	right := x + int(node.LayoutGetWidth())
+	for row := y; row < y+int(node.LayoutGetHeight()) && row < len(c.rows); row++ {
+		end := c.rowEnd(row)
+		if end <= left || end > right {
+			continue
+		}
+
+		for col := end; col < end+spacing; col++ {
+			c.set(col, row, canvasCell{R: ' ', Set: true})
+		}
+	}
+}
+
+// clear sets the cells in the given area to spaces.
+func (c *canvas) clear(x, y, width, height int) {
+	for row := y; row < y+height; row++ {
+		for col := x; col < x+width; col++ {
+			c.set(col, row, canvasCell{R: ' ', Set: true})
+		}
+	}
+}
+
+// set sets the cell at the given position. Positions outside of the
+// canvas are ignored.
+func (c *canvas) set(x, y int, cell canvasCell) {
+	if x < 0 || y < 0 || y >= len(c.rows) {
+		return
+	}
+
+	row := c.rows[y]
+	for len(row) <= x {
+		row = append(row, canvasCell{})
+	}
+	row[x] = cell
+	c.rows[y] = row
+}
+
+// rowEnd returns the column after the last cell that is set in the row.
+func (c *canvas) rowEnd(y int) int {
+	row := c.rows[y]
+	for i := len(row) - 1; i >= 0; i-- {
+		if row[i].Set {
+			return i + 1
+		}
+	}
+
+	return 0
+}
+
+// write writes the canvas to w with a newline between each row. If style
+// is non-nil, it is called to apply the style to each run of cells with
+// the same style.
+func (c *canvas) write(w io.Writer, style textStyler) {
+	var b strings.Builder
+	for y := range c.rows {
+		if y > 0 {
+			b.WriteByte('\n')
+		}
+
+		row := c.rows[y][:c.rowEnd(y)]
+		for i := 0; i < len(row); {
+			// Find the run of cells with the same style
+			start := i
+			for i < len(row) && row[i].Style == row[start].Style {
+				i++
+			}
+
+			var run strings.Builder
+			for _, cell := range row[start:i] {
+				if cell.Set {
+					run.WriteRune(cell.R)
+				} else {
+					run.WriteByte(' ')
+				}
+			}
+
+			text := run.String()
+			if style != nil && row[start].Style != (TextStyle{}) {
+				text = style(row[start].Style, text)
+			}
+
+			b.WriteString(text)
+		}
+	}
+
+	io.WriteString(w, b.String())
+}
+
+// paintOrder returns the children of parent in the order they're drawn.
+// Children are ordered by their z-index (see LayoutComponent.ZIndex).
+// Children with the same z-index are drawn with absolutely positioned
+// children after the others and otherwise keep their order, so later
+// children are drawn over earlier children.
+func paintOrder(parent *flex.Node) []*flex.Node {
+	children := parent.Children
+	for _, child := range children {
+		if nodeZIndex(child) != 0 || nodeAbsolute(child) {
+			children = append([]*flex.Node(nil), children...)
+			sort.SliceStable(children, func(i, j int) bool {
+				zi, zj := nodeZIndex(children[i]), nodeZIndex(children[j])
+				if zi != zj {
+					return zi < zj
+				}
+
+				return !nodeAbsolute(children[i]) && nodeAbsolute(children[j])
+			})
+			break
+		}
+	}
+
+	return children
+}
+
+// nodeAbsolute returns true if the node is absolutely positioned.
+func nodeAbsolute(n *flex.Node) bool {
+	return n.Style.PositionType == flex.PositionTypeAbsolute
+}
+
+// nodeZIndex returns the z-index of the node.
+func nodeZIndex(n *flex.Node) int {
+	ctx, ok := n.Context.(*parentContext)
+	if !ok {
+		return 0
+	}
+
+	c, ok := ctx.C.(*LayoutComponent)
+	if !ok {
+		return 0
+	}
+
+	return c.z
+}
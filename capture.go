@@ -0,0 +1,167 @@
+package glint
+
+import (
+	"io"
+	"os"
+	"time"
+)
+
+// CaptureOutput redirects stdout and stderr through a pipe until Close is
+// called. Anything written to them while captured is written permanently
+// above the live components, the same as Writer. This prevents libraries
+// that write directly to stdout or stderr from corrupting the output while
+// the render loop is active.
+//
+// On unix systems, this redirects the stdout and stderr file descriptors,
+// so everything that writes to them is captured, including the log
+// package, child processes, and cgo. If the renderer writes to stdout or
+// stderr, such as a renderer created by New, it is changed to write to the
+// original file so that the output isn't captured.
+//
+// On other systems, this works by replacing the os.Stdout and os.Stderr
+// variables, so anything that retrieved those values before this was
+// called will continue to write directly to the original files.
+//
+// Child processes started while capturing that inherit stdout or stderr
+// are captured as well. Close only waits a short time for such processes
+// to close the pipe, so output they write after Close is lost.
+//
+// Calling this multiple times does nothing.
+func (d *Document) CaptureOutput() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.capture != nil || d.closed {
+		return nil
+	}
+
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+
+	origStdout, origStderr := os.Stdout, os.Stderr
+	stdout, stderr, restore, err := redirectStdio(pw)
+	if err != nil {
+		pr.Close()
+		pw.Close()
+		return err
+	}
+
+	c := &outputCapture{
+		restoreStdio: restore,
+		r:            pr,
+		w:            pw,
+		doneCh:       make(chan struct{}),
+	}
+	go func() {
+		defer close(c.doneCh)
+		defer pr.Close()
+		io.Copy(d.Writer(), pr)
+	}()
+
+	// Anything we write to the terminal must go to the original files
+	// since stdout and stderr are now the pipe.
+	replace := func(w *io.Writer) {
+		var to io.Writer
+		switch *w {
+		case io.Writer(origStdout):
+			to = stdout
+		case io.Writer(origStderr):
+			to = stderr
+		default:
+			return
+		}
+
+		from := *w
+		*w = to
+		c.undo = append(c.undo, func() { *w = from })
+	}
+	if out := rendererOutput(d.r); out != nil {
+		replace(out)
+	}
+	if r := terminalRenderer(d.r); r != nil {
+		// The renderer reads the terminal size from its output when the
+		// terminal is resized, so it must use our copy as well.
+		r.outputChanged()
+		c.undo = append(c.undo, r.outputChanged)
+	}
+	if d.input != nil && d.input.out != nil {
+		replace(&d.input.out)
+	}
+	if stdout != origStdout {
+		c.undo = append(c.undo, func() {
+			stdout.Close()
+			stderr.Close()
+		})
+	}
+
+	d.capture = c
+	return nil
+}
+
+// rendererOutput returns a pointer to the output of the renderer if it
+// is a renderer that writes to an io.Writer. This returns nil otherwise.
+func rendererOutput(r Renderer) *io.Writer {
+	switch r := r.(type) {
+	case *TerminalRenderer:
+		return &r.Output
+	case *LineRenderer:
+		return &r.Output
+	case *CastRenderer:
+		return rendererOutput(r.Renderer)
+	default:
+		return nil
+	}
+}
+
+// terminalRenderer returns the TerminalRenderer that r draws with, if any.
+func terminalRenderer(r Renderer) *TerminalRenderer {
+	switch r := r.(type) {
+	case *TerminalRenderer:
+		return r
+	case *CastRenderer:
+		return r.Renderer
+	default:
+		return nil
+	}
+}
+
+// outputCapture is the state for CaptureOutput.
+type outputCapture struct {
+	restoreStdio func()
+	undo         []func()
+	r, w         *os.File
+	doneCh       chan struct{}
+}
+
+// restore restores the original stdout and stderr and waits for all the
+// captured output to be written to the document. This must not be called
+// with the document lock held.
+//
+// Child processes that inherited stdout or stderr keep the pipe open, so
+// we only wait a short time for the rest of the output before we stop
+// reading. Anything the child writes after that is lost.
+func (c *outputCapture) restore() {
+	c.restoreStdio()
+	c.w.Close()
+
+	select {
+	case <-c.doneCh:
+	case <-time.After(captureDrainTimeout):
+		c.r.Close()
+		<-c.doneCh
+	}
+}
+
+// captureDrainTimeout is how long restore waits for the capture pipe to
+// be closed by everything else that has it open.
+const captureDrainTimeout = 100 * time.Millisecond
+
+// restoreOutputs changes anything we changed to write to the original
+// stdout and stderr back and closes our copies of the originals. This must
+// be called with the document lock held after restore.
+func (c *outputCapture) restoreOutputs() {
+	for _, f := range c.undo {
+		f()
+	}
+}
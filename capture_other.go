@@ -0,0 +1,21 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package glint
+
+import (
+	"os"
+)
+
+// redirectStdio replaces the os.Stdout and os.Stderr variables with w.
+// This returns the original stdout and stderr along with a function to
+// restore the variables.
+func redirectStdio(w *os.File) (stdout, stderr *os.File, restore func(), err error) {
+	stdout, stderr = os.Stdout, os.Stderr
+	os.Stdout = w
+	os.Stderr = w
+	return stdout, stderr, func() {
+		os.Stdout = stdout
+		os.Stderr = stderr
+	}, nil
+}
@@ -0,0 +1,47 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package glint
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// redirectStdio points the stdout and stderr file descriptors at w. This
+// returns new files for the original stdout and stderr along with a
+// function to restore the file descriptors. The caller must close the
+// returned files after restoring.
+func redirectStdio(w *os.File) (stdout, stderr *os.File, restore func(), err error) {
+	outFd, err := unix.Dup(1)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	errFd, err := unix.Dup(2)
+	if err != nil {
+		unix.Close(outFd)
+		return nil, nil, nil, err
+	}
+
+	stdout = os.NewFile(uintptr(outFd), os.Stdout.Name())
+	stderr = os.NewFile(uintptr(errFd), os.Stderr.Name())
+	restore = func() {
+		unix.Dup2(outFd, 1)
+		unix.Dup2(errFd, 2)
+	}
+
+	if err := unix.Dup2(int(w.Fd()), 1); err != nil {
+		stdout.Close()
+		stderr.Close()
+		return nil, nil, nil, err
+	}
+	if err := unix.Dup2(int(w.Fd()), 2); err != nil {
+		restore()
+		stdout.Close()
+		stderr.Close()
+		return nil, nil, nil, err
+	}
+
+	return stdout, stderr, restore, nil
+}
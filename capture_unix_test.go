@@ -0,0 +1,97 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package glint
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestDocument_captureOutputFd(t *testing.T) {
+	require := require.New(t)
+
+	var buf bytes.Buffer
+	d := New()
+	d.SetRenderer(&LineRenderer{Output: &buf})
+	d.Append(Text("live"))
+	require.NoError(d.CaptureOutput())
+
+	// Anything written to the file descriptors is captured, even if it
+	// doesn't use the os.Stdout and os.Stderr variables.
+	log.New(os.Stderr, "", 0).Println("logged")
+	unix.Write(1, []byte("raw\n"))
+
+	require.NoError(d.Close())
+	require.Equal("logged\nraw\nlive\n", buf.String())
+}
+
+func TestDocument_captureOutputRenderer(t *testing.T) {
+	require := require.New(t)
+
+	// A renderer writing to stdout writes to the original stdout while
+	// capturing so that its output isn't captured.
+	r := &LineRenderer{Output: os.Stdout}
+	d := New()
+	d.SetRenderer(r)
+	require.NoError(d.CaptureOutput())
+	out, ok := r.Output.(*os.File)
+	require.True(ok)
+	require.NotEqual(os.Stdout, out)
+	require.NotEqual(uintptr(1), out.Fd())
+
+	require.NoError(d.Close())
+	require.Equal(os.Stdout, r.Output)
+}
+
+func TestDocument_captureOutputResize(t *testing.T) {
+	require := require.New(t)
+
+	// The terminal size is read from the original stdout while capturing
+	// since stdout is the pipe.
+	r := &TerminalRenderer{Output: os.Stdout}
+	r.sizeFile = os.Stdout
+	d := New()
+	d.SetRenderer(r)
+	require.NoError(d.CaptureOutput())
+	require.Equal(r.Output, r.sizeFile)
+	require.NotEqual(uintptr(1), r.sizeFile.Fd())
+
+	require.NoError(d.Close())
+	require.Equal(os.Stdout, r.sizeFile)
+}
+
+func TestDocument_captureOutputChild(t *testing.T) {
+	require := require.New(t)
+
+	var buf bytes.Buffer
+	d := New()
+	d.SetRenderer(&LineRenderer{Output: &buf})
+	require.NoError(d.CaptureOutput())
+
+	// A child process that inherited stdout keeps the pipe open.
+	cmd := exec.Command("sh", "-c", "echo child; exec sleep 5")
+	cmd.Stdout = os.Stdout
+	require.NoError(cmd.Start())
+	defer func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}()
+	require.Eventually(func() bool {
+		d.RenderFrame()
+		return buf.String() == "child\n"
+	}, 5*time.Second, time.Millisecond)
+
+	// Closing doesn't wait for the child to exit.
+	start := time.Now()
+	require.NoError(d.Close())
+	require.True(time.Since(start) < 2*time.Second)
+	require.Equal("child\n", buf.String())
+}
@@ -0,0 +1,123 @@
+package glint
+
+import (
+	"bytes"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// cell is a single character cell of rendered terminal output.
+type cell struct {
+	// R is the character in this cell.
+	R rune
+
+	// SGR is the set of SGR escape sequences (colors, bold, etc.) that are
+	// active for this cell. This is empty if the cell is unstyled.
+	SGR string
+}
+
+// parseCells parses rendered output into a grid of cells. The output
+// is expected to only contain newlines, printable characters, and SGR
+// escape sequences (such as those written by styleRender). Any other
+// escape sequences are ignored.
+func parseCells(b []byte) [][]cell {
+	var result [][]cell
+	var row []cell
+	var sgr string
+	for len(b) > 0 {
+		switch b[0] {
+		case '\n':
+			result = append(result, row)
+			row = nil
+			b = b[1:]
+			continue
+
+		case '\x1b':
+			// Find the end of the sequence. CSI sequences end with a byte
+			// in the range 0x40 to 0x7E.
+			end := 1
+			if len(b) > 1 && b[1] == '[' {
+				end = 2
+				for end < len(b) && (b[end] < 0x40 || b[end] > 0x7e) {
+					end++
+				}
+			}
+			if end >= len(b) {
+				b = nil
+				continue
+			}
+
+			seq := b[:end+1]
+			b = b[end+1:]
+			if seq[len(seq)-1] != 'm' {
+				continue
+			}
+
+			// A reset clears all of our styles, otherwise it is
+			// layered on top of our existing styles.
+			if params := seq[2 : len(seq)-1]; len(params) == 0 || bytes.Equal(params, sgrResetParam) {
+				sgr = ""
+			} else {
+				sgr += string(seq)
+			}
+
+			continue
+		}
+
+		r, size := utf8.DecodeRune(b)
+		b = b[size:]
+		row = append(row, cell{R: r, SGR: sgr})
+	}
+
+	return append(result, row)
+}
+
+// cellsWidth returns the number of columns the cells take up on a
+// terminal. Wide characters, such as CJK characters and emoji, take up
+// two columns.
+func cellsWidth(cells []cell) int {
+	width := 0
+	for _, c := range cells {
+		width += runewidth.RuneWidth(c.R)
+	}
+
+	return width
+}
+
+// hasWideCell returns true if any of the cells is a wide character.
+func hasWideCell(cells []cell) bool {
+	for _, c := range cells {
+		if runewidth.RuneWidth(c.R) > 1 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// writeCells writes the cells to the buffer along with the SGR sequences
+// necessary to style them. The style is always reset after writing.
+func writeCells(buf *bytes.Buffer, cells []cell) {
+	sgr := ""
+	for _, c := range cells {
+		if c.SGR != sgr {
+			if sgr != "" {
+				buf.WriteString(sgrReset)
+			}
+
+			buf.WriteString(c.SGR)
+			sgr = c.SGR
+		}
+
+		buf.WriteRune(c.R)
+	}
+
+	if sgr != "" {
+		buf.WriteString(sgrReset)
+	}
+}
+
+const sgrReset = "\x1b[0m"
+
+var sgrResetParam = []byte("0")
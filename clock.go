@@ -0,0 +1,45 @@
+package glint
+
+import (
+	"context"
+	"time"
+)
+
+// Clock is the source of time for a Document and its components. The
+// default clock uses the system time. Tests can replace the clock using
+// Document.SetClock to make time-based components such as spinners
+// reproducible; see TestClock.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// After waits for the duration to elapse and then sends the current
+	// time on the returned channel. This has the same semantics as
+	// time.After.
+	After(d time.Duration) <-chan time.Time
+}
+
+// WithClock inserts the clock into the context. This is done automatically
+// by Document for components.
+func WithClock(ctx context.Context, c Clock) context.Context {
+	return context.WithValue(ctx, clockCtxKey, c)
+}
+
+// ClockFromContext returns the Clock in the context. If no Clock is found,
+// a Clock using the system time is returned so that this is always safe
+// to call. Components should use this rather than the time package directly.
+func ClockFromContext(ctx context.Context) Clock {
+	if v, ok := ctx.Value(clockCtxKey).(Clock); ok && v != nil {
+		return v
+	}
+
+	return systemClock{}
+}
+
+// systemClock is a Clock using the system time.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+const clockCtxKey = glintCtxKey("clock")
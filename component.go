@@ -69,6 +69,29 @@ type ComponentMounter interface {
 	Unmount(context.Context)
 }
 
+// ComponentDirtier allows components to report whether they have changed
+// since they were last rendered. This is optional: components that don't
+// implement this are assumed to always be dirty.
+//
+// Before each frame, Dirty is called on every component in the previously
+// rendered tree. If all of them implement this interface and report they
+// are clean, and nothing else about the document changed, the frame is
+// skipped entirely: no Body functions are called, no layout is calculated,
+// and the renderer isn't called. If any component is dirty, the entire tree
+// is rebuilt as usual.
+//
+// Dirty is called from the render loop and so must not block and must be
+// safe to call concurrently with any methods that modify the component.
+type ComponentDirtier interface {
+	Component
+
+	// Dirty returns true if the component would render differently than
+	// it did the last time Body was called. The context is the document's
+	// render context. It doesn't contain values set by parent components
+	// or for the component itself, such as IsFocused.
+	Dirty(context.Context) bool
+}
+
 // componentLayout can be implemented to set custom layout settings
 // for the component. This can only be implemented by internal components
 // since we use an internal library.
@@ -17,6 +17,7 @@ type SparklineComponent struct {
 	PeakStyle []glint.StyleOption
 
 	values *ring.Ring
+	dirty  bool
 }
 
 // Sparkline creates a SparklineComponent with the given set of initial values.
@@ -33,6 +34,7 @@ func Sparkline(values []uint) *SparklineComponent {
 func (c *SparklineComponent) Set(values []uint) {
 	c.Lock()
 	defer c.Unlock()
+	c.dirty = true
 	c.values = ring.New(len(values))
 	for _, v := range values {
 		c.values.Value = v
@@ -46,6 +48,7 @@ func (c *SparklineComponent) Set(values []uint) {
 func (c *SparklineComponent) Append(values ...uint) {
 	c.Lock()
 	defer c.Unlock()
+	c.dirty = true
 	for _, v := range values {
 		c.values.Value = v
 		c.values = c.values.Next()
@@ -62,10 +65,19 @@ func (c *SparklineComponent) valuesSlice() []uint {
 	return result
 }
 
+// Dirty implements glint.ComponentDirtier. The sparkline is dirty when
+// values are set or appended.
+func (c *SparklineComponent) Dirty(context.Context) bool {
+	c.Lock()
+	defer c.Unlock()
+	return c.dirty
+}
+
 func (c *SparklineComponent) Body(context.Context) glint.Component {
 	c.Lock()
 	defer c.Unlock()
 
+	c.dirty = false
 	values := c.valuesSlice()
 
 	// If we have nothing we render nothing
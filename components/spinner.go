@@ -25,12 +25,23 @@ type SpinnerComponent struct {
 	last time.Time
 }
 
-func (c *SpinnerComponent) Body(context.Context) glint.Component {
-	current := time.Now()
-	if c.last.IsZero() || current.Sub(c.last) > 150*time.Millisecond {
+func (c *SpinnerComponent) Body(ctx context.Context) glint.Component {
+	current := glint.ClockFromContext(ctx).Now()
+	if c.next(current) {
 		c.last = current
 		c.s.Next()
 	}
 
 	return glint.Text(c.s.Current())
 }
+
+// Dirty implements glint.ComponentDirtier. The spinner is dirty when it
+// is time to show the next frame.
+func (c *SpinnerComponent) Dirty(ctx context.Context) bool {
+	return c.next(glint.ClockFromContext(ctx).Now())
+}
+
+// next returns true if the spinner should advance to the next frame.
+func (c *SpinnerComponent) next(current time.Time) bool {
+	return c.last.IsZero() || current.Sub(c.last) > 150*time.Millisecond
+}
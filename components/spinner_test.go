@@ -0,0 +1,24 @@
+package components
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mitchellh/go-glint"
+)
+
+func TestSpinner(t *testing.T) {
+	require := require.New(t)
+
+	d := glint.NewTestDriver(t, Spinner())
+	first := d.Step(0)
+	require.NotEmpty(first)
+
+	// Within the interval the frame doesn't change
+	require.Equal(first, d.Step(100*time.Millisecond))
+
+	// After the interval it does
+	require.NotEqual(first, d.Step(100*time.Millisecond))
+}
@@ -8,6 +8,7 @@ import (
 )
 
 // Stopwatch creates a new stopwatch component that starts at the given time.
+// The elapsed time is measured using the clock from the render context.
 func Stopwatch(start time.Time) *StopwatchComponent {
 	return &StopwatchComponent{
 		start: start,
@@ -16,8 +17,21 @@ func Stopwatch(start time.Time) *StopwatchComponent {
 
 type StopwatchComponent struct {
 	start time.Time
+	last  string
 }
 
-func (c *StopwatchComponent) Body(context.Context) glint.Component {
-	return glint.Text(time.Now().Sub(c.start).Truncate(100 * time.Millisecond).String())
+func (c *StopwatchComponent) Body(ctx context.Context) glint.Component {
+	c.last = c.elapsed(ctx)
+	return glint.Text(c.last)
+}
+
+// Dirty implements glint.ComponentDirtier. The stopwatch is dirty when
+// the displayed elapsed time changes.
+func (c *StopwatchComponent) Dirty(ctx context.Context) bool {
+	return c.elapsed(ctx) != c.last
+}
+
+func (c *StopwatchComponent) elapsed(ctx context.Context) string {
+	now := glint.ClockFromContext(ctx).Now()
+	return now.Sub(c.start).Truncate(100 * time.Millisecond).String()
 }
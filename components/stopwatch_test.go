@@ -0,0 +1,21 @@
+package components
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mitchellh/go-glint"
+)
+
+func TestStopwatch(t *testing.T) {
+	require := require.New(t)
+
+	d := glint.NewTestDriver(t)
+	d.Document.Append(Stopwatch(d.Clock.Now()))
+
+	require.Equal("0s", d.Step(0))
+	require.Equal("1.2s", d.Step(1250*time.Millisecond))
+	require.Equal("1m1.2s", d.Step(time.Minute))
+}
@@ -1,12 +1,18 @@
 package glint
 
 import (
+	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"os"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	sshterm "golang.org/x/crypto/ssh/terminal"
+
 	"github.com/mitchellh/go-glint/flex"
 )
 
@@ -25,18 +31,68 @@ type Document struct {
 	r           Renderer
 	els         []Component
 	refreshRate time.Duration
+	maxFPS      uint
 	prevRoot    *flex.Node
+	prevNodes   []*flex.Node
 	mounted     map[ComponentMounter]struct{}
+	clock       Clock
 	paused      bool
 	closed      bool
+
+	// dirty is true if the document changed in a way that requires the
+	// next frame to be fully rendered, regardless of ComponentDirtier.
+	dirty bool
+
+	// invalid is set atomically by Invalidate so that it can be called
+	// without the lock. invalidateCh is notified when the document is
+	// invalidated so that the render loop can wake up.
+	invalid        uint32
+	invalidateOnce sync.Once
+	invalidateCh   chan struct{}
+
+	// printed is the text queued to be written above the live components
+	// on the next frame, and partial is the text written to Writer that
+	// hasn't yet been terminated by a newline.
+	printed []string
+	partial []byte
+
+	// capture is non-nil if CaptureOutput is active.
+	capture *outputCapture
+
+	// input is non-nil if EnableInput is active. mouse is true if
+	// EnableMouse was called. signals is true if RestoreOnSignal was
+	// called.
+	input   *documentInput
+	mouse   bool
+	signals bool
+
+	// focused is the context of the node of the focused component, if any.
+	// focusPending is a component given to Focus that wasn't in the tree
+	// yet. focusOrder is the list of contexts of the nodes of focusable
+	// components in the last rendered tree, in tree order.
+	focused      *parentContext
+	focusPending Component
+	focusOrder   []*parentContext
+
+	// mountHook, if set, is called after a component is mounted or
+	// unmounted. This is used by TestDriver to record these events.
+	mountHook func(c ComponentMounter, mounted bool)
 }
 
-// New returns a Document that will output to stdout.
+// New returns a Document that will output to stdout. If stdout is a
+// terminal then a TerminalRenderer is used. Otherwise, a LineRenderer is
+// used so that output to logs, pipes, etc. remains readable.
 func New() *Document {
 	var d Document
-	d.SetRenderer(&TerminalRenderer{
-		Output: os.Stdout,
-	})
+	if sshterm.IsTerminal(int(os.Stdout.Fd())) {
+		d.SetRenderer(&TerminalRenderer{
+			Output: os.Stdout,
+		})
+	} else {
+		d.SetRenderer(&LineRenderer{
+			Output: os.Stdout,
+		})
+	}
 
 	return &d
 }
@@ -48,20 +104,83 @@ func (d *Document) SetRenderer(r Renderer) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 	d.r = r
+	d.invalidate()
+}
+
+// SetClock sets the clock used by the render loop and given to components
+// via the context (see ClockFromContext). If this isn't set then the system
+// time is used. Changes to this will have no impact on active render loops.
+func (d *Document) SetClock(c Clock) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.clock = c
 }
 
-// SetRefreshRate sets the rate at which output is rendered.
+// SetRefreshRate sets the rate at which output is rendered when the
+// document isn't invalidated. This is necessary for components that change
+// without the document being invalidated, such as spinners. With dirty
+// tracking (see ComponentDirtier), frames where nothing changed are cheap.
 func (d *Document) SetRefreshRate(dur time.Duration) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 	d.refreshRate = dur
 }
 
+// SetMaxFPS sets the maximum number of frames rendered per second by the
+// render loop. Invalidations that happen faster than this are coalesced
+// into a single frame. If this is zero, a default of 60 is used. Changes to
+// this will have no impact on active render loops.
+func (d *Document) SetMaxFPS(fps uint) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.maxFPS = fps
+}
+
+// Invalidate requests that a new frame be rendered. An active render loop
+// wakes immediately (subject to the maximum FPS, see SetMaxFPS) rather than
+// waiting for the refresh rate. The frame is always fully rendered, even
+// if every component reports that it is clean (see ComponentDirtier).
+//
+// Append, Set, Printf, and writes to Writer invalidate the document
+// automatically. Components should call this when their state changes.
+// Multiple calls before the next frame result in only one frame. This is
+// safe to call at any time, including from Body.
+func (d *Document) Invalidate() {
+	atomic.StoreUint32(&d.invalid, 1)
+	d.wake()
+}
+
+// invalidate marks the document dirty and wakes the render loop. This
+// must be called with the lock held.
+func (d *Document) invalidate() {
+	d.dirty = true
+	d.wake()
+}
+
+// wake wakes the render loop if it is sleeping.
+func (d *Document) wake() {
+	select {
+	case d.invalidated() <- struct{}{}:
+	default:
+		// A frame is already pending
+	}
+}
+
+// invalidated returns the channel that is notified on invalidation.
+func (d *Document) invalidated() chan struct{} {
+	d.invalidateOnce.Do(func() {
+		d.invalidateCh = make(chan struct{}, 1)
+	})
+
+	return d.invalidateCh
+}
+
 // Append appends components to the document.
 func (d *Document) Append(el ...Component) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 	d.els = append(d.els, el...)
+	d.invalidate()
 }
 
 // Set sets the components for the document. This will replace all
@@ -70,11 +189,56 @@ func (d *Document) Set(els ...Component) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 	d.els = els
+	d.invalidate()
+}
+
+// Printf formats according to a format specifier and queues the result
+// to be written permanently above the live components on the next frame.
+// This is the same as if a finalized Text component was inserted before all
+// the other components. A trailing newline is optional.
+func (d *Document) Printf(format string, v ...interface{}) {
+	s := strings.TrimSuffix(fmt.Sprintf(format, v...), "\n")
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.printed = append(d.printed, s)
+	d.invalidate()
+}
+
+// Writer returns an io.Writer that queues any lines written to it to be
+// written permanently above the live components on the next frame. See
+// Printf for more details. Only complete lines are queued: a final line that
+// isn't terminated with a newline is buffered until it is, or until Close.
+//
+// This can be used as the output for loggers so that logs can be written
+// while the render loop is active without corrupting the output.
+func (d *Document) Writer() io.Writer {
+	return documentWriter{d: d}
+}
+
+type documentWriter struct {
+	d *Document
+}
+
+func (w documentWriter) Write(p []byte) (int, error) {
+	d := w.d
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.partial = append(d.partial, p...)
+	if idx := bytes.LastIndexByte(d.partial, '\n'); idx >= 0 {
+		d.printed = append(d.printed, string(d.partial[:idx]))
+		d.partial = append([]byte(nil), d.partial[idx+1:]...)
+		d.invalidate()
+	}
+
+	return len(p), nil
 }
 
 // Close ensures that all elements are unmounted by finalizing all the
 // output and then calling RenderFrame. Users of Document should ensure
-// that Close is always called.
+// that Close is always called. This also restores stdout and stderr if
+// CaptureOutput was called.
 func (d *Document) Close() error {
 	d.mu.Lock()
 	if d.closed {
@@ -82,8 +246,28 @@ func (d *Document) Close() error {
 		return nil
 	}
 
-	for i, el := range d.els {
-		d.els[i] = Finalize(el)
+	// If we're capturing output, restore it first so that everything
+	// captured is written out before we finalize.
+	if capture := d.capture; capture != nil {
+		d.capture = nil
+		d.mu.Unlock()
+		capture.restore()
+		d.mu.Lock()
+		capture.restoreOutputs()
+	}
+
+	// Stop reading input and restore the terminal.
+	if d.input != nil {
+		d.input.stop()
+		d.input = nil
+	}
+
+	d.finalize()
+
+	// Flush any partial line written to our Writer.
+	if len(d.partial) > 0 {
+		d.printed = append(d.printed, string(d.partial))
+		d.partial = nil
 	}
 
 	d.closed = true
@@ -103,6 +287,16 @@ func (d *Document) Close() error {
 	return nil
 }
 
+// finalize finalizes all the components in the document. This must be
+// called with the lock held.
+func (d *Document) finalize() {
+	for i, el := range d.els {
+		d.els[i] = Finalize(el)
+	}
+
+	d.invalidate()
+}
+
 // Pause will pause the renderer. This will case RenderFrame to do nothing
 // until Resume is called. The use case for this is if you want to wait for
 // input (stdin) or any other reason.
@@ -120,23 +314,45 @@ func (d *Document) Resume() {
 }
 
 // Render starts a render loop that continues to render until the
-// context is cancelled. This will render at the configured refresh rate.
-// If the refresh rate is changed, it will not affect an active render loop.
-// You must cancel and restart the render loop.
+// context is cancelled. This will render at the configured refresh rate
+// and additionally whenever the document is invalidated (see Invalidate),
+// at most at the configured maximum FPS. If these settings are changed, it
+// will not affect an active render loop. You must cancel and restart the
+// render loop.
 func (d *Document) Render(ctx context.Context) {
 	d.mu.Lock()
 	dur := d.refreshRate
+	fps := d.maxFPS
+	clock := d.clock
+	invalidateCh := d.invalidated()
+	var refreshCh <-chan struct{}
+	if r, ok := d.r.(RendererRefresher); ok {
+		refreshCh = r.Refresh()
+	}
 	d.mu.Unlock()
 	if dur == 0 {
 		dur = time.Second / 24
 	}
+	if fps == 0 {
+		fps = 60
+	}
+	if clock == nil {
+		clock = systemClock{}
+	}
+	minDur := time.Second / time.Duration(fps)
 
 	for {
+		// Any pending invalidation is handled by this frame.
+		select {
+		case <-invalidateCh:
+		default:
+		}
+
 		// Render. We time the render so that we can adapt the framerate
 		// if the render is taking too long.
-		start := time.Now()
+		start := clock.Now()
 		d.RenderFrame()
-		renderDur := time.Now().Sub(start)
+		renderDur := clock.Now().Sub(start)
 
 		// If our context is canceled, end.
 		if ctx.Err() != nil {
@@ -156,61 +372,109 @@ func (d *Document) Render(ctx context.Context) {
 			}
 		}
 
-		time.Sleep(sleepDur)
+		// Sleep until our next frame, until the document is invalidated,
+		// or until the renderer requests a frame, such as when the terminal
+		// is resized.
+		select {
+		case <-ctx.Done():
+			return
+		case <-clock.After(sleepDur):
+		case <-invalidateCh:
+		case <-refreshCh:
+		}
+
+		// If we woke up early, wait until the minimum frame duration has
+		// passed. Any invalidations in the meantime are coalesced into the
+		// next frame.
+		if wait := minDur - clock.Now().Sub(start); wait > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-clock.After(wait):
+			}
+		}
 	}
 }
 
 // RenderFrame will render a single frame and return.
 //
-// If a manual size is not configured, this will recalcualte the window
-// size on each call. This typically requires a syscall. This is a bit
-// expensive but something we can optimize in the future if it ends up being
-// a real source of FPS issues.
+// If a manual size is not configured, the renderer may need to determine
+// the window size on each call. TerminalRenderer caches the size and
+// listens for resizes on platforms that support it.
+//
+// If nothing changed since the last frame (see ComponentDirtier), the
+// frame is skipped and the renderer isn't called.
 func (d *Document) RenderFrame() {
+	d.renderFrame()
+}
+
+// renderFrame renders a single frame. This returns true if the frame was
+// skipped because nothing changed since the last frame.
+func (d *Document) renderFrame() bool {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
 	// If we're paused do nothing.
 	if d.paused {
-		return
+		return false
 	}
 
 	// If we don't have a renderer set, then don't render anything.
 	if d.r == nil {
-		return
+		return false
+	}
+
+	// If we have any printed text, it goes in front of all our components
+	// as finalized text so it is drawn once and then removed.
+	if len(d.printed) > 0 {
+		els := make([]Component, 0, len(d.printed)+len(d.els))
+		for _, s := range d.printed {
+			els = append(els, Finalize(Text(s)))
+		}
+
+		d.els = append(els, d.els...)
+
+		// Our components moved, so we move the previous nodes with them so
+		// that they're still reconciled by position.
+		prev := make([]*flex.Node, len(d.printed), len(d.printed)+len(d.prevNodes))
+		d.prevNodes = append(prev, d.prevNodes...)
+		d.printed = nil
+		d.dirty = true
 	}
 
 	// Our context
-	ctx := WithRenderer(context.Background(), d.r)
+	ctx := d.context()
 
 	// Setup our root node
 	root := d.r.LayoutRoot()
 	if root == nil {
-		return
+		return false
+	}
+
+	// If nothing changed since the last frame then there is nothing to do.
+	if d.clean(ctx, root) {
+		return true
 	}
 
 	// Build our render tree
-	tree(ctx, root, Fragment(d.els...), false)
+	tree(ctx, root, d.prevNodes, Fragment(d.els...), false)
+	d.prevNodes = root.Children
 
 	// Calculate the layout
 	flex.CalculateLayout(root, flex.Undefined, flex.Undefined, flex.DirectionLTR)
+	treeLayout(root)
 
 	// Fix any text nodes that need to be fixed.
 	d.handleNodes(ctx, root, nil)
 
 	// If the height of the root is zero then we do nothing.
 	if uint(root.LayoutGetHeight()) == 0 {
-		return
+		d.dirty = true
+		return false
 	}
 
-	// Render the tree
-	d.r.RenderRoot(root, d.prevRoot)
-
-	// Store how much we drew
-	height := uint(root.LayoutGetHeight())
-
 	// If our component list is prefixed with finalized components, we
-	// prune these out and do not re-render them.
+	// prune these out after rendering and do not re-render them.
 	finalIdx := -1
 	for i, el := range d.els {
 		child := root.GetChild(i)
@@ -226,24 +490,101 @@ func (d *Document) RenderFrame() {
 			break
 		}
 
-		// If this is finalized, then we have to subtract from the
-		// height the height of this child since we're not going to redraw.
-		// Then continue until we find one that isn't finalized.
-		height -= uint(child.LayoutGetHeight())
+		// If this is finalized, then we continue until we find one that
+		// isn't finalized.
 		finalIdx = i
 	}
+
+	// Render the tree
+	if r, ok := d.r.(frameRenderer); ok {
+		clock := d.clock
+		if clock == nil {
+			clock = systemClock{}
+		}
+
+		r.beginFrame(frameInfo{finalized: finalIdx + 1, clock: clock})
+	}
+	d.r.RenderRoot(root, d.prevRoot)
+
+	// Store how much we drew
+	height := uint(root.LayoutGetHeight())
+
 	if finalIdx >= 0 {
+		// We have to subtract from the height everything we drew for the
+		// finalized children, including any margins, since we're not
+		// going to redraw it.
+		height -= uint(nodeBottom(root.GetChild(finalIdx)))
+
 		// Change our elements
 		els := d.els[finalIdx+1:]
 		d.els = make([]Component, len(els))
 		copy(d.els, els)
 
+		// The finalized nodes will never be reconciled again.
+		d.prevNodes = append([]*flex.Node(nil), root.Children[finalIdx+1:]...)
+
 		// Reset the height on the root so that it reflects this change
 		root.Layout.Dimensions[flex.DimensionHeight] = float32(height)
 	}
 
-	// Store our previous root
-	d.prevRoot = root
+	// Store our previous root. If we pruned finalized components then
+	// the next frame must be rendered without them. The nodes are reused by
+	// the next frame so we store a snapshot of the layout.
+	d.prevRoot = treeSnapshot(root)
+	d.dirty = finalIdx >= 0
+	return false
+}
+
+// context returns the context given to components. This must be called
+// with the lock held.
+func (d *Document) context() context.Context {
+	ctx := WithRenderer(context.Background(), d.r)
+	ctx = context.WithValue(ctx, documentCtxKey, d)
+	ctx = context.WithValue(ctx, focusedCtxKey, focusedValue{
+		Node:    d.focused,
+		Pending: d.focusPending,
+	})
+	if d.clock != nil {
+		ctx = WithClock(ctx, d.clock)
+	}
+
+	return ctx
+}
+
+// clean returns true if the previous frame can be reused for a frame with
+// the given root. This must be called with the lock held.
+func (d *Document) clean(ctx context.Context, root *flex.Node) bool {
+	// We always reset the invalid flag, since this frame handles it.
+	if atomic.SwapUint32(&d.invalid, 0) == 1 || d.dirty || d.prevRoot == nil {
+		return false
+	}
+
+	// The root style determines the size available to components, so if
+	// it changed then we need to render.
+	if !flex.StyleEqual(&root.Style, &d.prevRoot.Style) {
+		return false
+	}
+
+	return !nodesDirty(ctx, d.prevRoot)
+}
+
+// nodesDirty returns true if any component in the tree rooted at parent
+// is dirty. Components that don't implement ComponentDirtier are dirty.
+func nodesDirty(ctx context.Context, parent *flex.Node) bool {
+	for _, child := range parent.Children {
+		if tctx, ok := child.Context.(treeContext); ok {
+			dc, ok := tctx.Component().(ComponentDirtier)
+			if !ok || dc.Dirty(ctx) {
+				return true
+			}
+		}
+
+		if nodesDirty(ctx, child) {
+			return true
+		}
+	}
+
+	return false
 }
 
 func (d *Document) handleNodes(
@@ -278,6 +619,9 @@ func (d *Document) handleNodes(
 
 						// Notify
 						mc.Mount(ctx)
+						if d.mountHook != nil {
+							d.mountHook(mc, true)
+						}
 					}
 				}
 			}
@@ -314,7 +658,14 @@ func (d *Document) handleNodes(
 	for mc := range d.mounted {
 		if _, ok := seen[mc]; !ok {
 			mc.Unmount(ctx)
+			if d.mountHook != nil {
+				d.mountHook(mc, false)
+			}
 		}
 	}
 	d.mounted = seen
+
+	// Update our focus order and blur the focused component if it was
+	// removed from the tree.
+	d.updateFocus(parent)
 }
@@ -3,10 +3,15 @@ package glint
 import (
 	"bytes"
 	"context"
+	"fmt"
+	"os"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/mitchellh/go-glint/flex"
 )
 
 func TestDocument_mountUnmount(t *testing.T) {
@@ -87,6 +92,69 @@ func TestDocument_renderingWithoutLayout(t *testing.T) {
 	require.Zero(t, atomic.LoadUint32(&c.mount))
 }
 
+func TestDocument_printf(t *testing.T) {
+	require := require.New(t)
+
+	r := &StringRenderer{}
+	d := New()
+	d.SetRenderer(r)
+	d.Append(Text("live"))
+
+	// Printed text is drawn above the live components
+	d.Printf("hello %s", "world")
+	d.Printf("trailing\n")
+	d.RenderFrame()
+	require.Equal("hello world\ntrailing\nlive", r.Builder.String())
+
+	// Printed text is only drawn once
+	d.RenderFrame()
+	require.Equal("live", r.Builder.String())
+}
+
+func TestDocument_writer(t *testing.T) {
+	require := require.New(t)
+
+	var buf bytes.Buffer
+	d := New()
+	d.SetRenderer(&LineRenderer{Output: &buf})
+	d.Append(Text("live"))
+
+	// Only complete lines are written
+	w := d.Writer()
+	w.Write([]byte("one\ntw"))
+	d.RenderFrame()
+	require.Equal("one\n", buf.String())
+
+	w.Write([]byte("o\nthree\nfo"))
+	d.RenderFrame()
+	require.Equal("one\ntwo\nthree\n", buf.String())
+
+	// Closing flushes the partial line
+	require.NoError(d.Close())
+	require.Equal("one\ntwo\nthree\nfo\nlive\n", buf.String())
+}
+
+func TestDocument_captureOutput(t *testing.T) {
+	require := require.New(t)
+
+	stdout, stderr := os.Stdout, os.Stderr
+
+	var buf bytes.Buffer
+	d := New()
+	d.SetRenderer(&LineRenderer{Output: &buf})
+	d.Append(Text("live"))
+	require.NoError(d.CaptureOutput())
+
+	fmt.Println("stray")
+	fmt.Fprintln(os.Stderr, "error")
+
+	// Closing restores and writes everything captured
+	require.NoError(d.Close())
+	require.Equal(stdout, os.Stdout)
+	require.Equal(stderr, os.Stderr)
+	require.Equal("stray\nerror\nlive\n", buf.String())
+}
+
 type testMount struct {
 	terminalComponent
 
@@ -101,3 +169,193 @@ var (
 	_ Component        = (*testMount)(nil)
 	_ ComponentMounter = (*testMount)(nil)
 )
+
+func TestDocument_dirty(t *testing.T) {
+	require := require.New(t)
+
+	c := &testDirty{text: "one"}
+	d := NewTestDriver(t, c)
+
+	// First frame always renders
+	require.Equal("one", d.Step(0))
+	require.Equal(uint32(1), c.body)
+
+	// Clean, so nothing is built or rendered
+	require.Equal("one", d.Step(0))
+	require.Equal(uint32(1), c.body)
+	require.Len(d.Frames, 1)
+
+	// Dirty
+	c.text = "two"
+	c.dirty = true
+	require.Equal("two", d.Step(0))
+	require.Equal(uint32(2), c.body)
+	require.Len(d.Frames, 2)
+
+	// Changing the document renders
+	d.Document.Append(Text("hello"))
+	require.Equal("two\nhello", d.Step(0))
+	require.Len(d.Frames, 3)
+
+	// Changing the root size renders
+	d.Renderer.Width = 40
+	require.Equal("two\nhello", d.Step(0))
+	require.Len(d.Frames, 4)
+
+	// A component that doesn't implement ComponentDirtier always renders
+	d.Document.Append(TextFunc(func(rows, cols uint) string { return "dynamic" }))
+	d.Step(0)
+	d.Step(0)
+	require.Len(d.Frames, 6)
+	require.Equal(uint32(6), c.body)
+}
+
+type testDirty struct {
+	text  string
+	body  uint32
+	dirty bool
+}
+
+func (c *testDirty) Body(context.Context) Component {
+	c.dirty = false
+	c.body++
+	return Text(c.text)
+}
+
+func (c *testDirty) Dirty(context.Context) bool { return c.dirty }
+
+func TestDocument_renderCancel(t *testing.T) {
+	d := New()
+	d.SetRenderer(&StringRenderer{})
+	d.SetRefreshRate(time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	doneCh := make(chan struct{})
+	go func() {
+		defer close(doneCh)
+		d.Render(ctx)
+	}()
+
+	cancel()
+	select {
+	case <-doneCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("render loop didn't exit")
+	}
+}
+
+func TestDocument_renderInvalidate(t *testing.T) {
+	require := require.New(t)
+
+	r := &testCountRenderer{}
+	d := New()
+	d.SetRenderer(r)
+	d.SetRefreshRate(time.Hour)
+	d.Append(Text("hello"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Render(ctx)
+
+	// Each invalidation renders a frame long before the refresh rate
+	for i := uint32(1); i <= 3; i++ {
+		require.Eventually(func() bool {
+			return atomic.LoadUint32(&r.count) == i
+		}, 5*time.Second, time.Millisecond)
+
+		d.Invalidate()
+	}
+}
+
+func TestDocument_renderMaxFPS(t *testing.T) {
+	require := require.New(t)
+
+	r := &testCountRenderer{}
+	clock := NewTestClock(time.Time{})
+	d := New()
+	d.SetRenderer(r)
+	d.SetClock(clock)
+	d.SetRefreshRate(time.Hour)
+	d.SetMaxFPS(10)
+	d.Append(Text("hello"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Render(ctx)
+
+	// waitBlocked waits until the render loop is waiting on n timers.
+	waitBlocked := func(n int) {
+		require.Eventually(func() bool {
+			clock.mu.Lock()
+			defer clock.mu.Unlock()
+			return len(clock.waiters) == n
+		}, 5*time.Second, time.Millisecond)
+	}
+
+	// The first frame renders and then the loop waits for the refresh.
+	waitBlocked(1)
+	require.Equal(uint32(1), atomic.LoadUint32(&r.count))
+
+	// Invalidating wakes the loop, but it waits for the minimum frame
+	// duration. Invalidations in the meantime are coalesced.
+	d.Invalidate()
+	waitBlocked(2)
+	for i := 0; i < 10; i++ {
+		d.Invalidate()
+	}
+	clock.Advance(50 * time.Millisecond)
+	waitBlocked(2)
+	require.Equal(uint32(1), atomic.LoadUint32(&r.count))
+
+	// After the minimum frame duration, a single frame renders.
+	clock.Advance(50 * time.Millisecond)
+	waitBlocked(2)
+	require.Equal(uint32(2), atomic.LoadUint32(&r.count))
+
+	// Nothing else renders until the document is invalidated again.
+	clock.Advance(100 * time.Millisecond)
+	waitBlocked(2)
+	require.Equal(uint32(2), atomic.LoadUint32(&r.count))
+}
+
+// testCountRenderer is a StringRenderer that counts rendered frames.
+type testCountRenderer struct {
+	StringRenderer
+	count uint32
+}
+
+func (r *testCountRenderer) RenderRoot(root, prev *flex.Node) {
+	r.StringRenderer.RenderRoot(root, prev)
+	atomic.AddUint32(&r.count, 1)
+}
+
+func TestDocument_renderPrev(t *testing.T) {
+	require := require.New(t)
+
+	r := &testPrevRenderer{}
+	d := New()
+	d.SetRenderer(r)
+
+	value := "hello\nworld"
+	d.Append(TextFunc(func(rows, cols uint) string { return value }))
+	d.RenderFrame()
+	value = "hello"
+	d.RenderFrame()
+
+	// The previous root has the previous layout even though the node for
+	// the text was reused.
+	require.Len(r.prev, 2)
+	require.Nil(r.prev[0])
+	require.Equal(float32(2), r.prev[1].GetChild(0).LayoutGetHeight())
+}
+
+// testPrevRenderer is a StringRenderer that records the prev roots.
+type testPrevRenderer struct {
+	StringRenderer
+	prev []*flex.Node
+}
+
+func (r *testPrevRenderer) RenderRoot(root, prev *flex.Node) {
+	r.StringRenderer.RenderRoot(root, prev)
+	r.prev = append(r.prev, prev)
+}
@@ -17,3 +17,9 @@ type finalizedComponent struct {
 func (c *finalizedComponent) Body(context.Context) Component {
 	return c.Component
 }
+
+// Dirty implements ComponentDirtier. The finalized component itself never
+// changes; the component it wraps is checked separately.
+func (c *finalizedComponent) Dirty(context.Context) bool {
+	return false
+}
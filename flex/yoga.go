@@ -449,6 +449,12 @@ func styleEq(s1, s2 *Style) bool {
 	return true
 }
 
+// StyleEqual returns true if the styles are equal. Undefined values are
+// equal to each other.
+func StyleEqual(s1, s2 *Style) bool {
+	return styleEq(s1, s2) && feq(s1.AspectRatio, s2.AspectRatio)
+}
+
 // NodeCopyStyle copies style
 func NodeCopyStyle(dstNode *Node, srcNode *Node) {
 	if !styleEq(&dstNode.Style, &srcNode.Style) {
@@ -0,0 +1,239 @@
+package glint
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/mitchellh/go-glint/flex"
+)
+
+// ComponentFocusable is implemented by components that can be focused.
+// The focused component is given key events before any other components
+// (see ComponentInputHandler) and can render differently by checking
+// IsFocused in Body.
+//
+// Focus moves between the focusable components in the tree in tree order
+// with Tab and Shift-Tab if the key isn't handled by any component, or
+// programmatically with Document.Focus. When the focused component is
+// removed from the tree, it is blurred and nothing is focused.
+type ComponentFocusable interface {
+	Component
+
+	// Focusable returns true if the component can currently be focused.
+	// Components that return false are skipped when moving focus.
+	Focusable() bool
+}
+
+// IsFocused returns true if the component whose Body or HandleKey is being
+// called with ctx is the focused component.
+func IsFocused(ctx context.Context) bool {
+	v, _ := ctx.Value(isFocusedCtxKey).(bool)
+	return v
+}
+
+// Focus focuses the component c. The component should be focusable (see
+// ComponentFocusable) and in the document. If the component isn't in the
+// tree when the next frame is rendered, it is blurred. If c is nil, the
+// focused component is blurred.
+//
+// Focus follows the position of the component in the tree, so components
+// that are created again each time their parent's Body is called stay
+// focused as long as they're reconciled with the same node (see Key).
+func (d *Document) Focus(c Component) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.focused = nil
+	d.focusPending = nil
+	if c != nil {
+		for _, node := range d.prevNodes {
+			if n := focusNode(node, c); n != nil {
+				d.focused = n.Context.(*parentContext)
+				break
+			}
+		}
+
+		// If the component isn't rendered yet, we look for it when the
+		// next frame is built.
+		if d.focused == nil {
+			d.focusPending = c
+		}
+	}
+
+	d.invalidate()
+}
+
+// Focused returns the focused component or nil if no component is focused.
+func (d *Document) Focused() Component {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.focused != nil {
+		return d.focused.C
+	}
+
+	return d.focusPending
+}
+
+// FocusNext moves focus to the next focusable component in the tree,
+// wrapping around to the first. If nothing is focused, the first focusable
+// component is focused.
+func (d *Document) FocusNext() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.moveFocus(1)
+}
+
+// FocusPrev moves focus to the previous focusable component in the tree,
+// wrapping around to the last. If nothing is focused, the last focusable
+// component is focused.
+func (d *Document) FocusPrev() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.moveFocus(-1)
+}
+
+// moveFocus moves the focus by delta in the focus order, skipping any
+// components that are not currently focusable. This must be called with
+// the lock held.
+func (d *Document) moveFocus(delta int) {
+	n := len(d.focusOrder)
+	if n == 0 {
+		return
+	}
+
+	idx := -1
+	for i, pctx := range d.focusOrder {
+		if pctx == d.focused {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 && delta < 0 {
+		idx = n
+	}
+
+	for i := 0; i < n; i++ {
+		idx = ((idx+delta)%n + n) % n
+		if d.focusOrder[idx].C.(ComponentFocusable).Focusable() {
+			d.focused = d.focusOrder[idx]
+			d.focusPending = nil
+			d.invalidate()
+			return
+		}
+	}
+}
+
+// updateFocus updates the focus order for the tree rooted at root and
+// resolves any component given to Focus to its node. The focused node is
+// blurred if it was removed from the tree. This must be called with the
+// lock held after the tree is built.
+func (d *Document) updateFocus(root *flex.Node) {
+	d.focusOrder = focusables(nil, root)
+	if d.focusPending != nil {
+		if n := focusNode(root, d.focusPending); n != nil {
+			d.focused = n.Context.(*parentContext)
+		}
+
+		d.focusPending = nil
+	}
+
+	if d.focused != nil {
+		found := false
+		for _, pctx := range d.focusOrder {
+			if found = pctx == d.focused; found {
+				break
+			}
+		}
+		if !found {
+			d.focused = nil
+		}
+	}
+}
+
+// focusables appends the contexts of the nodes with focusable components
+// in the tree rooted at node to result, in tree order.
+func focusables(result []*parentContext, node *flex.Node) []*parentContext {
+	if pctx, ok := node.Context.(*parentContext); ok {
+		if _, ok := pctx.C.(ComponentFocusable); ok {
+			result = append(result, pctx)
+		}
+	}
+
+	for _, child := range node.Children {
+		result = focusables(result, child)
+	}
+
+	return result
+}
+
+// focusNode returns the node for the component c in the tree rooted at
+// node, or nil if it isn't found.
+func focusNode(node *flex.Node, c Component) *flex.Node {
+	if pctx, ok := node.Context.(*parentContext); ok && sameComponent(pctx.C, c) {
+		return node
+	}
+
+	for _, child := range node.Children {
+		if n := focusNode(child, c); n != nil {
+			return n
+		}
+	}
+
+	return nil
+}
+
+// contextNode returns the node with the context pctx in the tree rooted
+// at node, or nil if it isn't found.
+func contextNode(node *flex.Node, pctx *parentContext) *flex.Node {
+	if node.Context == pctx {
+		return node
+	}
+
+	for _, child := range node.Children {
+		if n := contextNode(child, pctx); n != nil {
+			return n
+		}
+	}
+
+	return nil
+}
+
+// sameComponent returns true if a and b are the same component. Unlike ==,
+// this doesn't panic for components with types that are not comparable.
+func sameComponent(a, b Component) bool {
+	if a == nil || b == nil {
+		return false
+	}
+
+	ta := reflect.TypeOf(a)
+	if ta != reflect.TypeOf(b) || !ta.Comparable() {
+		return false
+	}
+
+	return a == b
+}
+
+// focusedValue is the focus state of the document stored in a context
+// while the tree is built.
+type focusedValue struct {
+	// Node is the context of the focused node. Pending is the component
+	// given to Focus if it wasn't in the tree yet.
+	Node    *parentContext
+	Pending Component
+}
+
+// isFocused returns true if the component c with the node context pctx
+// is focused.
+func (v focusedValue) isFocused(pctx *parentContext, c Component) bool {
+	if v.Node != nil {
+		return v.Node == pctx
+	}
+
+	return sameComponent(v.Pending, c)
+}
+
+type focusCtxKeyType int
+
+const (
+	focusedCtxKey focusCtxKeyType = iota
+	isFocusedCtxKey
+)
@@ -0,0 +1,118 @@
+package glint
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocument_focus(t *testing.T) {
+	require := require.New(t)
+
+	a := &testFocusable{name: "a", focusable: true}
+	b := &testFocusable{name: "b", focusable: false}
+	c := &testFocusable{name: "c", focusable: true}
+	d := NewTestDriver(t, a, Layout(b, c))
+	require.Equal("a\nb\nc", d.Step(0))
+	require.Nil(d.Document.Focused())
+
+	// Tab moves through focusable components in order
+	d.Input("\t")
+	require.Equal(a, d.Document.Focused())
+	require.Equal("[a]\nb\nc", d.Step(0))
+	d.Input("\t")
+	require.Equal(c, d.Document.Focused())
+	require.Equal("a\nb\n[c]", d.Step(0))
+	d.Input("\t")
+	require.Equal(a, d.Document.Focused())
+
+	// Shift-Tab moves backwards
+	d.Input("\x1b[Z")
+	require.Equal(c, d.Document.Focused())
+
+	// Programmatic focus
+	d.Document.Focus(a)
+	require.Equal("[a]\nb\nc", d.Step(0))
+
+	// Removing the focused component blurs it
+	d.Document.Set(Layout(b, c))
+	require.Equal("b\nc", d.Step(0))
+	require.Nil(d.Document.Focused())
+}
+
+func TestDocument_focusRebuilt(t *testing.T) {
+	require := require.New(t)
+
+	// The focusable child is created again each frame.
+	parent := &testStateFunc{f: func(context.Context) Component {
+		return &testFocusable{name: "a", focusable: true}
+	}}
+	d := NewTestDriver(t, parent, &testFocusable{name: "b", focusable: true})
+	require.Equal("a\nb", d.Step(0))
+
+	// Focus stays with the child across frames.
+	d.Input("\t")
+	require.Equal("[a]\nb", d.Step(0))
+	require.Equal("[a]\nb", d.Step(0))
+	d.Input("x")
+	require.Equal([]string{"x focused"}, d.Document.Focused().(*testFocusable).events)
+	d.Input("\t")
+	require.Equal("a\n[b]", d.Step(0))
+}
+
+func TestDocument_focusInput(t *testing.T) {
+	require := require.New(t)
+
+	a := &testFocusable{name: "a", focusable: true}
+	b := &testFocusable{name: "b", focusable: true}
+	d := NewTestDriver(t, a, b)
+	d.Step(0)
+
+	// Without focus, the events go in order
+	d.Input("x")
+	require.Equal([]string{"x"}, a.events)
+	require.Equal([]string(nil), b.events)
+
+	// The focused component gets the event first
+	d.Document.Focus(b)
+	d.Step(0)
+	d.Input("y")
+	require.Equal([]string{"x"}, a.events)
+	require.Equal([]string{"y focused"}, b.events)
+
+	// Handlers can handle tab
+	b.handleTab = true
+	d.Input("\t")
+	require.Equal(b, d.Document.Focused())
+}
+
+type testFocusable struct {
+	name      string
+	focusable bool
+	handleTab bool
+	events    []string
+}
+
+func (c *testFocusable) Focusable() bool { return c.focusable }
+
+func (c *testFocusable) Body(ctx context.Context) Component {
+	if IsFocused(ctx) {
+		return Text("[" + c.name + "]")
+	}
+
+	return Text(c.name)
+}
+
+func (c *testFocusable) HandleKey(ctx context.Context, ev KeyEvent) bool {
+	if ev.Key == KeyTab {
+		return c.handleTab
+	}
+
+	v := ev.String()
+	if IsFocused(ctx) {
+		v += " focused"
+	}
+	c.events = append(c.events, v)
+	return true
+}
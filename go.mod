@@ -6,10 +6,12 @@ require (
 	github.com/cheggaaa/pb/v3 v3.0.5
 	github.com/containerd/console v1.0.1
 	github.com/gookit/color v1.3.1
+	github.com/mattn/go-runewidth v0.0.7
 	github.com/mitchellh/go-testing-interface v1.14.1
 	github.com/mitchellh/go-wordwrap v1.0.1
 	github.com/morikuni/aec v1.0.0
 	github.com/stretchr/testify v1.6.1
 	github.com/tj/go-spin v1.1.0
 	golang.org/x/crypto v0.0.0-20200820211705-5c72a883971a
+	golang.org/x/sys v0.0.0-20200916030750-2334cc1a136f
 )
@@ -0,0 +1,468 @@
+package glint
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	sshterm "golang.org/x/crypto/ssh/terminal"
+
+	"github.com/mitchellh/go-glint/flex"
+)
+
+// KeyCode identifies the key of a KeyEvent.
+type KeyCode int
+
+const (
+	// KeyRune is a printable character or a character combined with a
+	// modifier such as ctrl. The character is in KeyEvent.Rune.
+	KeyRune KeyCode = iota
+	KeyEnter
+	KeyTab
+	KeyBackspace
+	KeyEscape
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyHome
+	KeyEnd
+	KeyPageUp
+	KeyPageDown
+	KeyInsert
+	KeyDelete
+	KeyF1
+	KeyF2
+	KeyF3
+	KeyF4
+	KeyF5
+	KeyF6
+	KeyF7
+	KeyF8
+	KeyF9
+	KeyF10
+	KeyF11
+	KeyF12
+
+	// KeyPaste is text pasted with bracketed paste. The text is in
+	// KeyEvent.Paste.
+	KeyPaste
+)
+
+// keyNone and keyPasteStart are used internally by the parser for
+// sequences that don't result in an event.
+const (
+	keyNone       KeyCode = -1
+	keyPasteStart KeyCode = -2
+)
+
+var keyNames = map[KeyCode]string{
+	KeyEnter:     "enter",
+	KeyTab:       "tab",
+	KeyBackspace: "backspace",
+	KeyEscape:    "esc",
+	KeyUp:        "up",
+	KeyDown:      "down",
+	KeyLeft:      "left",
+	KeyRight:     "right",
+	KeyHome:      "home",
+	KeyEnd:       "end",
+	KeyPageUp:    "pgup",
+	KeyPageDown:  "pgdown",
+	KeyInsert:    "insert",
+	KeyDelete:    "delete",
+	KeyF1:        "f1",
+	KeyF2:        "f2",
+	KeyF3:        "f3",
+	KeyF4:        "f4",
+	KeyF5:        "f5",
+	KeyF6:        "f6",
+	KeyF7:        "f7",
+	KeyF8:        "f8",
+	KeyF9:        "f9",
+	KeyF10:       "f10",
+	KeyF11:       "f11",
+	KeyF12:       "f12",
+	KeyPaste:     "paste",
+}
+
+func (k KeyCode) String() string {
+	if k == KeyRune {
+		return "rune"
+	}
+
+	return keyNames[k]
+}
+
+// KeyMod is a set of modifiers held down for a KeyEvent.
+type KeyMod uint8
+
+const (
+	ModShift KeyMod = 1 << iota
+	ModAlt
+	ModCtrl
+)
+
+// KeyEvent is a key press read from the input. See Document.EnableInput.
+type KeyEvent struct {
+	Key  KeyCode
+	Rune rune
+	Mod  KeyMod
+
+	// Paste is the pasted text for KeyPaste.
+	Paste string
+}
+
+// String returns a readable representation of the key such as "a",
+// "ctrl+c", or "shift+tab". This is useful for matching keys.
+func (e KeyEvent) String() string {
+	var b strings.Builder
+	if e.Mod&ModCtrl != 0 {
+		b.WriteString("ctrl+")
+	}
+	if e.Mod&ModAlt != 0 {
+		b.WriteString("alt+")
+	}
+	if e.Mod&ModShift != 0 {
+		b.WriteString("shift+")
+	}
+
+	switch e.Key {
+	case KeyRune:
+		if e.Rune == ' ' {
+			b.WriteString("space")
+		} else {
+			b.WriteRune(e.Rune)
+		}
+
+	default:
+		b.WriteString(e.Key.String())
+	}
+
+	return b.String()
+}
+
+// ComponentInputHandler allows components to handle key events when input
+// is enabled on a Document (see Document.EnableInput).
+type ComponentInputHandler interface {
+	Component
+
+	// HandleKey is called for each key event. This should return true if
+	// the event was handled, in which case no other components are given
+	// the event.
+	//
+	// Events are given to components in the tree that was last rendered.
+	// The focused component (see ComponentFocusable) and its parents are
+	// given the event first, followed by all other components with children
+	// before their parents. IsFocused can be used with ctx to check if this
+	// component is focused. This is called outside of the render loop, so it
+	// is safe to modify the document. The document is invalidated after
+	// each event so that any changes are rendered.
+	HandleKey(ctx context.Context, ev KeyEvent) bool
+}
+
+// EnableInput starts reading key events from in and giving them to any
+// components that implement ComponentInputHandler. This is usually called
+// with os.Stdin.
+//
+// If in is a terminal, it is put into raw mode so that keys are read as
+// they're pressed without being echoed, and bracketed paste is enabled if
+// the renderer draws to a terminal. Signal generation is left enabled so
+// that ctrl+c still interrupts the program. The terminal is restored on
+// Close.
+//
+// Since signals such as SIGINT exit the program without calling Close by
+// default, the terminal is left in raw mode if the program is interrupted.
+// Programs that handle these signals should call Close from their handler.
+// Programs that don't can call RestoreOnSignal.
+//
+// Reading from in can't be interrupted, so after Close the goroutine
+// reading input exits after the next read returns. Calling this multiple
+// times does nothing.
+func (d *Document) EnableInput(in io.Reader) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.input != nil || d.closed {
+		return nil
+	}
+
+	input := &documentInput{doneCh: make(chan struct{})}
+	if f, ok := in.(*os.File); ok && sshterm.IsTerminal(int(f.Fd())) {
+		restore, err := makeRaw(f)
+		if err != nil {
+			return err
+		}
+		input.restore = restore
+
+		if r, ok := d.r.(terminalOutputer); ok {
+			input.out = r.terminalOutput()
+		}
+	}
+
+	if input.out != nil {
+		io.WriteString(input.out, pasteEnable)
+		if d.mouse && mouseOriginKnown(d.r) {
+			io.WriteString(input.out, mouseEnable)
+			input.mouse = true
+		}
+	}
+
+	if input.restore != nil && d.signals {
+		watchSignals(d, input)
+	}
+
+	d.input = input
+	go d.readInput(in, input)
+	return nil
+}
+
+// RestoreOnSignal restores the terminal if the program receives SIGINT or
+// SIGTERM while input is enabled (see EnableInput). This only restores the
+// terminal modes that were changed, such as raw mode, mouse reporting, and
+// the alternate screen of a Fullscreen TerminalRenderer. The signal is then
+// raised again so that the program exits the same as it would by default.
+//
+// This registers a handler for these signals with signal.Notify. Programs
+// that handle SIGINT or SIGTERM themselves should not call this and should
+// call Close from their own handler instead, otherwise they will receive
+// the signal twice. This does nothing on systems without these signals.
+func (d *Document) RestoreOnSignal() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.signals {
+		return
+	}
+
+	d.signals = true
+	if d.input != nil && d.input.restore != nil {
+		watchSignals(d, d.input)
+	}
+}
+
+// handleSignals waits for a signal on ch until input is stopped. If a
+// signal is received, the terminal is restored and then the signal is
+// given to raise.
+func (d *Document) handleSignals(ch chan os.Signal, input *documentInput, raise func(os.Signal)) {
+	defer signal.Stop(ch)
+
+	select {
+	case <-input.doneCh:
+	case sig := <-ch:
+		d.mu.Lock()
+		if d.input == input {
+			input.restoreTerminal()
+			if r := terminalRenderer(d.r); r != nil {
+				r.restoreTerminal()
+			}
+		}
+		d.mu.Unlock()
+
+		signal.Stop(ch)
+		raise(sig)
+	}
+}
+
+// readInput reads and dispatches input until in is closed or input
+// is stopped.
+func (d *Document) readInput(in io.Reader, input *documentInput) {
+	dataCh := make(chan []byte)
+	go func() {
+		defer close(dataCh)
+		buf := make([]byte, 1024)
+		for {
+			n, err := in.Read(buf)
+			if n > 0 {
+				select {
+				case dataCh <- append([]byte(nil), buf[:n]...):
+				case <-input.doneCh:
+					return
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	var p inputParser
+	var timeoutCh <-chan time.Time
+	for {
+		var events []interface{}
+		select {
+		case <-input.doneCh:
+			return
+
+		case data, ok := <-dataCh:
+			if !ok {
+				d.dispatchEvents(p.Flush())
+				return
+			}
+
+			events = p.Feed(data)
+
+		case <-timeoutCh:
+			events = p.Flush()
+		}
+
+		// If we have incomplete input, such as a lone escape, then we
+		// wait a short time for the rest of it before giving up.
+		timeoutCh = nil
+		if p.Pending() {
+			timeoutCh = time.After(inputEscapeTimeout)
+		}
+
+		d.dispatchEvents(events)
+	}
+}
+
+// dispatchEvents gives each key or mouse event to the components in the
+// tree that was last rendered. This must not be called with the lock held.
+func (d *Document) dispatchEvents(events []interface{}) {
+	if len(events) == 0 {
+		return
+	}
+
+	for _, ev := range events {
+		var ok bool
+		switch ev := ev.(type) {
+		case KeyEvent:
+			ok = d.dispatchKey(ev)
+		case MouseEvent:
+			ok = d.dispatchMouse(ev)
+		}
+		if !ok {
+			return
+		}
+	}
+
+	d.Invalidate()
+}
+
+// dispatchKey gives the event to the input handlers. The focused component
+// and then its parents are given the event first, followed by all other
+// handlers. If no handler handles Tab or Shift-Tab, the focus is moved.
+// This returns false if the document is closed.
+func (d *Document) dispatchKey(ev KeyEvent) bool {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return false
+	}
+	ctx := d.context()
+
+	var handlers []ComponentInputHandler
+	var focused Component
+	if d.focused != nil {
+		focused = d.focused.C
+		for _, node := range d.prevNodes {
+			for n := contextNode(node, d.focused); n != nil; n = n.Parent {
+				tctx, ok := n.Context.(treeContext)
+				if !ok {
+					break
+				}
+
+				if h, ok := tctx.Component().(ComponentInputHandler); ok {
+					handlers = append(handlers, h)
+				}
+			}
+		}
+	}
+	for _, node := range d.prevNodes {
+		handlers = inputHandlers(handlers, node)
+	}
+	d.mu.Unlock()
+
+	for i, h := range handlers {
+		// Skip the handler if we already called it.
+		dup := false
+		for _, prev := range handlers[:i] {
+			if dup = sameComponent(prev, h); dup {
+				break
+			}
+		}
+		if dup {
+			continue
+		}
+
+		hctx := context.WithValue(ctx, isFocusedCtxKey, sameComponent(h, focused))
+		if h.HandleKey(hctx, ev) {
+			return true
+		}
+	}
+
+	// Move focus if the key wasn't handled.
+	switch {
+	case ev.Key == KeyTab && ev.Mod == 0:
+		d.FocusNext()
+	case ev.Key == KeyTab && ev.Mod == ModShift:
+		d.FocusPrev()
+	}
+
+	return true
+}
+
+// inputHandlers appends the input handlers in the tree rooted at node to
+// result, with children before their parents.
+func inputHandlers(result []ComponentInputHandler, node *flex.Node) []ComponentInputHandler {
+	for _, child := range node.Children {
+		result = inputHandlers(result, child)
+	}
+
+	if tctx, ok := node.Context.(treeContext); ok {
+		if h, ok := tctx.Component().(ComponentInputHandler); ok {
+			result = append(result, h)
+		}
+	}
+
+	return result
+}
+
+// documentInput is the state for EnableInput.
+type documentInput struct {
+	restore func() error
+	out     io.Writer
+	mouse   bool
+	doneCh  chan struct{}
+}
+
+// stop stops reading input and restores the terminal. This must be called
+// with the document lock held.
+func (i *documentInput) stop() {
+	close(i.doneCh)
+	i.restoreTerminal()
+}
+
+// restoreTerminal disables the terminal modes we enabled and restores the
+// terminal from raw mode. This must be called with the document lock held.
+func (i *documentInput) restoreTerminal() {
+	if i.mouse {
+		io.WriteString(i.out, mouseDisable)
+		i.mouse = false
+	}
+	if i.out != nil {
+		io.WriteString(i.out, pasteDisable)
+		i.out = nil
+	}
+	if i.restore != nil {
+		i.restore()
+		i.restore = nil
+	}
+}
+
+// terminalOutputer is implemented by renderers that draw to a terminal so
+// that the document can set terminal modes such as bracketed paste.
+type terminalOutputer interface {
+	terminalOutput() io.Writer
+}
+
+const (
+	pasteEnable  = "\x1b[?2004h"
+	pasteDisable = "\x1b[?2004l"
+
+	// inputEscapeTimeout is how long we wait for the rest of an escape
+	// sequence before treating the escape as a key press.
+	inputEscapeTimeout = 50 * time.Millisecond
+)
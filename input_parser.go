@@ -0,0 +1,419 @@
+package glint
+
+import (
+	"bytes"
+	"unicode/utf8"
+)
+
+// inputParser parses key and mouse events from terminal input. Input is
+// given to Feed as it is read and may be split at any point, including
+// within escape sequences and UTF-8 characters. Events are either KeyEvent
+// or MouseEvent values.
+type inputParser struct {
+	buf   []byte
+	paste *bytes.Buffer
+}
+
+// Feed parses b and returns any complete events. Incomplete input is
+// buffered until the next call to Feed or Flush.
+func (p *inputParser) Feed(b []byte) []interface{} {
+	p.buf = append(p.buf, b...)
+	return p.parse(false)
+}
+
+// Flush returns the events for any buffered incomplete input. This should
+// be called when no more input arrives shortly after the last call to
+// Feed so that, for example, a lone escape is reported as the escape key
+// rather than waiting for the rest of an escape sequence. Incomplete
+// pasted text remains buffered.
+func (p *inputParser) Flush() []interface{} {
+	return p.parse(true)
+}
+
+// Pending returns true if there is buffered incomplete input that Flush
+// would return.
+func (p *inputParser) Pending() bool {
+	return p.paste == nil && len(p.buf) > 0
+}
+
+func (p *inputParser) parse(final bool) []interface{} {
+	var result []interface{}
+	for len(p.buf) > 0 {
+		// If we're in a bracketed paste, everything is text until the end.
+		if p.paste != nil {
+			idx := bytes.Index(p.buf, pasteEnd)
+			if idx < 0 {
+				// Keep anything that may be the start of the end sequence.
+				n := len(p.buf) - partialSuffix(p.buf, pasteEnd)
+				p.paste.Write(p.buf[:n])
+				p.buf = append(p.buf[:0], p.buf[n:]...)
+				break
+			}
+
+			p.paste.Write(p.buf[:idx])
+			result = append(result, KeyEvent{Key: KeyPaste, Paste: p.paste.String()})
+			p.paste = nil
+			p.buf = p.buf[idx+len(pasteEnd):]
+			continue
+		}
+
+		// Mouse events
+		if bytes.HasPrefix(p.buf, mouseStart) {
+			ev, n := parseMouse(p.buf, final)
+			if n > 0 {
+				p.buf = p.buf[n:]
+				if ev != nil {
+					result = append(result, *ev)
+				}
+
+				continue
+			}
+
+			// If the sequence isn't complete and this isn't final, then
+			// we wait for more input.
+			if !final {
+				break
+			}
+		}
+
+		ev, n := parseKey(p.buf, final)
+		if n == 0 {
+			break
+		}
+		p.buf = p.buf[n:]
+
+		switch ev.Key {
+		case keyNone:
+		case keyPasteStart:
+			p.paste = &bytes.Buffer{}
+		default:
+			result = append(result, ev)
+		}
+	}
+
+	// Don't hold on to the backing array forever.
+	if len(p.buf) == 0 {
+		p.buf = nil
+	}
+
+	return result
+}
+
+// parseKey parses a single event from the front of b and returns the number
+// of bytes used. If b is an incomplete sequence this returns zero unless
+// final is true, in which case the bytes are interpreted as best we can.
+func parseKey(b []byte, final bool) (KeyEvent, int) {
+	switch c := b[0]; {
+	case c == 0x1b:
+		return parseEscape(b, final)
+
+	case c == '\r' || c == '\n':
+		return KeyEvent{Key: KeyEnter}, 1
+
+	case c == '\t':
+		return KeyEvent{Key: KeyTab}, 1
+
+	case c == 0x7f || c == 0x08:
+		return KeyEvent{Key: KeyBackspace}, 1
+
+	case c == 0:
+		return KeyEvent{Key: KeyRune, Rune: ' ', Mod: ModCtrl}, 1
+
+	case c < 0x1b:
+		return KeyEvent{Key: KeyRune, Rune: rune('a' + c - 1), Mod: ModCtrl}, 1
+
+	case c < 0x20:
+		return KeyEvent{Key: KeyRune, Rune: rune('\\' + c - 0x1c), Mod: ModCtrl}, 1
+	}
+
+	if !utf8.FullRune(b) {
+		if final {
+			return KeyEvent{Key: keyNone}, len(b)
+		}
+
+		return KeyEvent{}, 0
+	}
+
+	r, n := utf8.DecodeRune(b)
+	if r == utf8.RuneError {
+		return KeyEvent{Key: keyNone}, n
+	}
+
+	return KeyEvent{Key: KeyRune, Rune: r}, n
+}
+
+func parseEscape(b []byte, final bool) (KeyEvent, int) {
+	escape := KeyEvent{Key: KeyEscape}
+	if len(b) == 1 {
+		if final {
+			return escape, 1
+		}
+
+		return KeyEvent{}, 0
+	}
+
+	switch b[1] {
+	case '[':
+		return parseCSI(b, final)
+
+	case 'O':
+		if len(b) < 3 {
+			if final {
+				return KeyEvent{Key: KeyRune, Rune: 'O', Mod: ModAlt}, 2
+			}
+
+			return KeyEvent{}, 0
+		}
+
+		if k, ok := ss3Keys[b[2]]; ok {
+			return KeyEvent{Key: k}, 3
+		}
+
+		return KeyEvent{Key: KeyRune, Rune: 'O', Mod: ModAlt}, 2
+
+	case 0x1b:
+		return escape, 1
+	}
+
+	// Escape followed by a key is that key with alt.
+	ev, n := parseKey(b[1:], final)
+	if n == 0 {
+		return KeyEvent{}, 0
+	}
+	if ev.Key == keyNone {
+		return escape, 1
+	}
+
+	ev.Mod |= ModAlt
+	return ev, n + 1
+}
+
+func parseCSI(b []byte, final bool) (KeyEvent, int) {
+	// Find the final byte. Parameter and intermediate bytes are in the
+	// range 0x20 to 0x3f.
+	end := -1
+	for i := 2; i < len(b); i++ {
+		if b[i] >= 0x40 && b[i] <= 0x7e {
+			end = i
+			break
+		}
+
+		if b[i] < 0x20 || b[i] > 0x3f {
+			// Invalid sequence, treat it as alt+[.
+			return KeyEvent{Key: KeyRune, Rune: '[', Mod: ModAlt}, 2
+		}
+	}
+	if end < 0 {
+		if final {
+			return KeyEvent{Key: KeyRune, Rune: '[', Mod: ModAlt}, 2
+		}
+
+		return KeyEvent{}, 0
+	}
+
+	params := csiParams(b[2:end])
+	param := func(i, def int) int {
+		if i < len(params) && params[i] > 0 {
+			return params[i]
+		}
+
+		return def
+	}
+
+	ev := KeyEvent{Key: keyNone}
+	switch c := b[end]; c {
+	case 'Z':
+		ev = KeyEvent{Key: KeyTab, Mod: ModShift}
+
+	case '~':
+		switch code := param(0, 0); {
+		case code == 200:
+			ev.Key = keyPasteStart
+		case code == 201:
+		default:
+			if k, ok := tildeKeys[code]; ok {
+				ev.Key = k
+			}
+		}
+
+	case 'u':
+		// CSI u encoding of keys with modifiers.
+		switch code := param(0, 0); code {
+		case 9:
+			ev.Key = KeyTab
+		case 13:
+			ev.Key = KeyEnter
+		case 27:
+			ev.Key = KeyEscape
+		case 127:
+			ev.Key = KeyBackspace
+		default:
+			ev = KeyEvent{Key: KeyRune, Rune: rune(code)}
+		}
+
+	default:
+		if k, ok := ss3Keys[c]; ok {
+			ev.Key = k
+		}
+	}
+
+	// The second parameter is the modifiers plus one.
+	if ev.Key >= 0 {
+		mod := param(1, 1) - 1
+		if mod&1 != 0 {
+			ev.Mod |= ModShift
+		}
+		if mod&(2|8) != 0 {
+			ev.Mod |= ModAlt
+		}
+		if mod&4 != 0 {
+			ev.Mod |= ModCtrl
+		}
+	}
+
+	return ev, end + 1
+}
+
+// parseMouse parses an SGR mouse sequence ("ESC [ < b ; x ; y M") at the
+// front of b. This returns zero if the sequence is incomplete, or a nil
+// event if the sequence is invalid.
+func parseMouse(b []byte, final bool) (*MouseEvent, int) {
+	end := -1
+	for i := len(mouseStart); i < len(b); i++ {
+		if b[i] == 'M' || b[i] == 'm' {
+			end = i
+			break
+		}
+
+		if (b[i] < '0' || b[i] > '9') && b[i] != ';' {
+			return nil, i
+		}
+	}
+	if end < 0 {
+		return nil, 0
+	}
+
+	params := csiParams(b[len(mouseStart):end])
+	if len(params) != 3 || params[1] < 1 || params[2] < 1 {
+		return nil, end + 1
+	}
+
+	code := params[0]
+	ev := &MouseEvent{
+		X:      params[1] - 1,
+		Y:      params[2] - 1,
+		Action: MousePress,
+	}
+	ev.RootX, ev.RootY = ev.X, ev.Y
+	if b[end] == 'm' {
+		ev.Action = MouseRelease
+	}
+	if code&32 != 0 {
+		ev.Action = MouseMotion
+	}
+	if code&4 != 0 {
+		ev.Mod |= ModShift
+	}
+	if code&8 != 0 {
+		ev.Mod |= ModAlt
+	}
+	if code&16 != 0 {
+		ev.Mod |= ModCtrl
+	}
+
+	switch button := code & 3; {
+	case code&64 != 0 && button == 0:
+		ev.Button = MouseWheelUp
+	case code&64 != 0 && button == 1:
+		ev.Button = MouseWheelDown
+	case code&64 != 0:
+		return nil, end + 1
+	case button == 0:
+		ev.Button = MouseLeft
+	case button == 1:
+		ev.Button = MouseMiddle
+	case button == 2:
+		ev.Button = MouseRight
+	default:
+		ev.Button = MouseNone
+	}
+
+	return ev, end + 1
+}
+
+// csiParams parses the semicolon-separated numeric parameters of a CSI
+// sequence. Missing or invalid parameters are zero.
+func csiParams(b []byte) []int {
+	var result []int
+	for _, part := range bytes.Split(b, []byte(";")) {
+		v := 0
+		for _, c := range part {
+			if c < '0' || c > '9' {
+				v = 0
+				break
+			}
+
+			v = v*10 + int(c-'0')
+		}
+
+		result = append(result, v)
+	}
+
+	return result
+}
+
+// partialSuffix returns the length of the longest suffix of b that is a
+// prefix of seq.
+func partialSuffix(b, seq []byte) int {
+	for n := len(seq) - 1; n > 0; n-- {
+		if len(b) >= n && bytes.Equal(b[len(b)-n:], seq[:n]) {
+			return n
+		}
+	}
+
+	return 0
+}
+
+// ss3Keys are the keys for the final byte of SS3 sequences (ESC O) and
+// CSI sequences (ESC [) that aren't "~" sequences.
+var ss3Keys = map[byte]KeyCode{
+	'A': KeyUp,
+	'B': KeyDown,
+	'C': KeyRight,
+	'D': KeyLeft,
+	'H': KeyHome,
+	'F': KeyEnd,
+	'P': KeyF1,
+	'Q': KeyF2,
+	'R': KeyF3,
+	'S': KeyF4,
+}
+
+// tildeKeys are the keys for the first parameter of "CSI n ~" sequences.
+var tildeKeys = map[int]KeyCode{
+	1:  KeyHome,
+	2:  KeyInsert,
+	3:  KeyDelete,
+	4:  KeyEnd,
+	5:  KeyPageUp,
+	6:  KeyPageDown,
+	7:  KeyHome,
+	8:  KeyEnd,
+	11: KeyF1,
+	12: KeyF2,
+	13: KeyF3,
+	14: KeyF4,
+	15: KeyF5,
+	17: KeyF6,
+	18: KeyF7,
+	19: KeyF8,
+	20: KeyF9,
+	21: KeyF10,
+	23: KeyF11,
+	24: KeyF12,
+}
+
+var (
+	pasteEnd   = []byte("\x1b[201~")
+	mouseStart = []byte("\x1b[<")
+)
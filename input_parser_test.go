@@ -0,0 +1,99 @@
+package glint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInputParser(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Input  []string
+		Events []string
+	}{
+		{"runes", []string{"ab"}, []string{"a", "b"}},
+		{"unicode", []string{"▄é"}, []string{"▄", "é"}},
+		{"split unicode", []string{"\xe2\x96", "\x84"}, []string{"▄"}},
+		{"enter", []string{"\r\n"}, []string{"enter", "enter"}},
+		{"tab", []string{"\t"}, []string{"tab"}},
+		{"backspace", []string{"\x7f\x08"}, []string{"backspace", "backspace"}},
+		{"ctrl", []string{"\x03\x00\x1f"}, []string{"ctrl+c", "ctrl+space", "ctrl+_"}},
+		{"alt", []string{"\x1ba\x1b\r"}, []string{"alt+a", "alt+enter"}},
+		{"escape", []string{"\x1b"}, []string{"esc"}},
+		{"double escape", []string{"\x1b\x1b"}, []string{"esc", "esc"}},
+		{"arrows", []string{"\x1b[A\x1b[B\x1b[C\x1b[D"}, []string{"up", "down", "right", "left"}},
+		{"ss3 arrows", []string{"\x1bOA\x1bOD"}, []string{"up", "left"}},
+		{"split sequence", []string{"\x1b", "[", "A"}, []string{"up"}},
+		{"home end", []string{"\x1b[H\x1b[F\x1b[1~\x1b[4~"}, []string{"home", "end", "home", "end"}},
+		{"editing", []string{"\x1b[2~\x1b[3~\x1b[5~\x1b[6~"}, []string{"insert", "delete", "pgup", "pgdown"}},
+		{"function", []string{"\x1bOP\x1b[15~\x1b[24~"}, []string{"f1", "f5", "f12"}},
+		{"modifiers", []string{"\x1b[1;5A\x1b[1;2B\x1b[1;3P\x1b[3;8~"}, []string{"ctrl+up", "shift+down", "alt+f1", "ctrl+alt+shift+delete"}},
+		{"shift tab", []string{"\x1b[Z"}, []string{"shift+tab"}},
+		{"csi u", []string{"\x1b[13;2u\x1b[97;5u"}, []string{"shift+enter", "ctrl+a"}},
+		{"unknown csi", []string{"\x1b[99~a"}, []string{"a"}},
+		{"incomplete csi", []string{"\x1b[1;"}, []string{"alt+[", "1", ";"}},
+		{"paste", []string{"\x1b[200~hello\x1b[A\x1b[201~a"}, []string{"paste", "a"}},
+		{"split paste", []string{"\x1b[200~he", "llo\x1b[2", "01~"}, []string{"paste"}},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			var p inputParser
+			var events []interface{}
+			for _, input := range tt.Input {
+				events = append(events, p.Feed([]byte(input))...)
+			}
+			events = append(events, p.Flush()...)
+
+			var actual []string
+			for _, ev := range events {
+				actual = append(actual, ev.(KeyEvent).String())
+			}
+			require.Equal(t, tt.Events, actual)
+		})
+	}
+}
+
+func TestInputParser_paste(t *testing.T) {
+	require := require.New(t)
+
+	var p inputParser
+	require.Empty(p.Feed([]byte("\x1b[200~hello\x1b")))
+	require.False(p.Pending())
+	require.Empty(p.Flush())
+
+	events := p.Feed([]byte("[A world\x1b[201~"))
+	require.Equal([]interface{}{KeyEvent{Key: KeyPaste, Paste: "hello\x1b[A world"}}, events)
+}
+
+func TestInputParser_pending(t *testing.T) {
+	require := require.New(t)
+
+	var p inputParser
+	require.Empty(p.Feed([]byte("\x1b")))
+	require.True(p.Pending())
+	require.Equal([]interface{}{KeyEvent{Key: KeyEscape}}, p.Flush())
+	require.False(p.Pending())
+}
+
+func TestInputParser_mouse(t *testing.T) {
+	require := require.New(t)
+
+	var p inputParser
+	events := p.Feed([]byte("\x1b[<0;3;2M\x1b[<0;3;2ma\x1b[<32;4;5M\x1b[<65;1;1M\x1b[<18;10;20M\x1b[<0;1"))
+	require.True(p.Pending())
+	require.Equal([]interface{}{
+		MouseEvent{Button: MouseLeft, Action: MousePress, X: 2, Y: 1, RootX: 2, RootY: 1},
+		MouseEvent{Button: MouseLeft, Action: MouseRelease, X: 2, Y: 1, RootX: 2, RootY: 1},
+		KeyEvent{Rune: 'a'},
+		MouseEvent{Button: MouseLeft, Action: MouseMotion, X: 3, Y: 4, RootX: 3, RootY: 4},
+		MouseEvent{Button: MouseWheelDown, Action: MousePress},
+		MouseEvent{Button: MouseRight, Action: MousePress, Mod: ModCtrl, X: 9, Y: 19, RootX: 9, RootY: 19},
+	}, events)
+
+	events = p.Feed([]byte(";1M"))
+	require.Equal([]interface{}{
+		MouseEvent{Button: MouseLeft, Action: MousePress},
+	}, events)
+}
@@ -0,0 +1,30 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package glint
+
+import (
+	"os"
+
+	"github.com/containerd/console"
+)
+
+// makeRaw puts the terminal f into raw mode. On Windows this also enables
+// virtual terminal input so that keys are read as escape sequences. The
+// returned function restores the previous state.
+func makeRaw(f *os.File) (func() error, error) {
+	c, err := console.ConsoleFromFile(f)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.SetRaw(); err != nil {
+		return nil, err
+	}
+
+	return c.Reset, nil
+}
+
+// watchSignals does nothing. In raw mode ctrl+c is read as input rather
+// than generating a signal.
+func watchSignals(d *Document, input *documentInput) {}
@@ -0,0 +1,127 @@
+package glint
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocument_enableInput(t *testing.T) {
+	require := require.New(t)
+
+	h := &testInputHandler{}
+	d := New()
+	d.SetRenderer(&StringRenderer{})
+	d.Append(h)
+	d.RenderFrame()
+
+	require.NoError(d.EnableInput(strings.NewReader("a\x1b[A\x1b")))
+	require.Eventually(func() bool {
+		return len(h.Events()) == 3
+	}, 5*time.Second, time.Millisecond)
+	require.Equal([]string{"a", "up", "esc"}, h.Events())
+	require.NoError(d.Close())
+}
+
+func TestDocument_handleSignals(t *testing.T) {
+	require := require.New(t)
+
+	var restored bool
+	var out bytes.Buffer
+	d := New()
+	d.SetRenderer(&TerminalRenderer{Output: &out, Rows: 5, Cols: 10, Fullscreen: true})
+	d.Append(Text("hello"))
+	d.RenderFrame()
+	input := &documentInput{
+		restore: func() error { restored = true; return nil },
+		out:     &out,
+		mouse:   true,
+		doneCh:  make(chan struct{}),
+	}
+	d.input = input
+
+	// A signal only restores the terminal and is then raised again.
+	out.Reset()
+	var raised os.Signal
+	ch := make(chan os.Signal, 1)
+	ch <- os.Interrupt
+	d.handleSignals(ch, input, func(sig os.Signal) { raised = sig })
+	require.True(restored)
+	require.Equal(mouseDisable+pasteDisable+cursorShow+altScreenExit, out.String())
+	require.Equal(os.Interrupt, raised)
+	require.Equal(input, d.input)
+	require.False(d.closed)
+
+	// Closing afterwards doesn't restore the terminal again.
+	out.Reset()
+	restored = false
+	require.NoError(d.Close())
+	require.False(restored)
+	require.NotContains(out.String(), pasteDisable)
+}
+
+func TestTestDriver_input(t *testing.T) {
+	require := require.New(t)
+
+	inner := &testInputHandler{}
+	outer := &testInputHandler{inner: inner, handle: "q"}
+	d := NewTestDriver(t, outer)
+	require.Equal("", d.Step(0))
+
+	// Children are given events before their parents.
+	d.Input("a")
+	require.Equal([]string{"a"}, inner.Events())
+	require.Equal([]string{"a"}, outer.Events())
+
+	// A handled event stops.
+	inner.handle = "b"
+	d.Input("b")
+	require.Equal([]string{"a", "b"}, inner.Events())
+	require.Equal([]string{"a"}, outer.Events())
+
+	// Handling an event renders
+	inner.handle = "q"
+	d.Input("q")
+	require.Equal("q", d.Step(0))
+}
+
+type testInputHandler struct {
+	sync.Mutex
+
+	inner  Component
+	handle string
+	events []string
+}
+
+func (c *testInputHandler) Body(context.Context) Component {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.inner != nil {
+		return c.inner
+	}
+	if len(c.events) > 0 && c.events[len(c.events)-1] == c.handle {
+		return Text(c.handle)
+	}
+
+	return nil
+}
+
+func (c *testInputHandler) HandleKey(ctx context.Context, ev KeyEvent) bool {
+	c.Lock()
+	defer c.Unlock()
+	c.events = append(c.events, ev.String())
+	return ev.String() == c.handle
+}
+
+func (c *testInputHandler) Events() []string {
+	c.Lock()
+	defer c.Unlock()
+	return append([]string(nil), c.events...)
+}
@@ -0,0 +1,19 @@
+package glint
+
+// Key sets a key on the component to give it a stable identity within its
+// siblings across renders. By default, components are matched to the
+// previous render by their position. If components are inserted, removed,
+// or reordered, keys allow them to keep their identity so that the layout
+// (and any state) from the previous render is reused.
+//
+// Keys must be comparable and should be unique among siblings.
+func Key(key interface{}, c Component) Component {
+	return &keyedComponent{key: key, inner: c}
+}
+
+type keyedComponent struct {
+	terminalComponent
+
+	key   interface{}
+	inner Component
+}
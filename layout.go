@@ -19,44 +19,290 @@ func Layout(inner ...Component) *LayoutComponent {
 type LayoutComponent struct {
 	inner   []Component
 	builder *layout.Builder
+	z       int
 }
 
 // Row sets the `flex-direction: row` property.
 func (c *LayoutComponent) Row() *LayoutComponent {
+	return c.FlexDirection(flex.FlexDirectionRow)
+}
+
+// RowReverse sets the `flex-direction: row-reverse` property.
+func (c *LayoutComponent) RowReverse() *LayoutComponent {
+	return c.FlexDirection(flex.FlexDirectionRowReverse)
+}
+
+// Column sets the `flex-direction: column` property. This is the default.
+func (c *LayoutComponent) Column() *LayoutComponent {
+	return c.FlexDirection(flex.FlexDirectionColumn)
+}
+
+// ColumnReverse sets the `flex-direction: column-reverse` property.
+func (c *LayoutComponent) ColumnReverse() *LayoutComponent {
+	return c.FlexDirection(flex.FlexDirectionColumnReverse)
+}
+
+// FlexDirection sets the `flex-direction` property.
+func (c *LayoutComponent) FlexDirection(v flex.FlexDirection) *LayoutComponent {
 	c.builder = c.builder.Raw(func(n *flex.Node) {
-		n.StyleSetFlexDirection(flex.FlexDirectionRow)
+		n.StyleSetFlexDirection(v)
 	})
 	return c
 }
 
-// MarginLeft sets the `margin-left` property.
-func (c *LayoutComponent) MarginLeft(x int) *LayoutComponent {
+// JustifyContent sets the `justify-content` property.
+func (c *LayoutComponent) JustifyContent(v flex.Justify) *LayoutComponent {
 	c.builder = c.builder.Raw(func(n *flex.Node) {
-		n.StyleSetMargin(flex.EdgeLeft, float32(x))
+		n.StyleSetJustifyContent(v)
 	})
 	return c
 }
 
-// MarginRight sets the `margin-left` property.
-func (c *LayoutComponent) MarginRight(x int) *LayoutComponent {
+// AlignItems sets the `align-items` property.
+func (c *LayoutComponent) AlignItems(v flex.Align) *LayoutComponent {
 	c.builder = c.builder.Raw(func(n *flex.Node) {
-		n.StyleSetMargin(flex.EdgeRight, float32(x))
+		n.StyleSetAlignItems(v)
 	})
 	return c
 }
 
-// PaddingLeft sets the `margin-left` property.
-func (c *LayoutComponent) PaddingLeft(x int) *LayoutComponent {
+// AlignSelf sets the `align-self` property.
+func (c *LayoutComponent) AlignSelf(v flex.Align) *LayoutComponent {
+	c.builder = c.builder.Raw(func(n *flex.Node) {
+		n.StyleSetAlignSelf(v)
+	})
+	return c
+}
+
+// AlignContent sets the `align-content` property.
+func (c *LayoutComponent) AlignContent(v flex.Align) *LayoutComponent {
+	c.builder = c.builder.Raw(func(n *flex.Node) {
+		n.StyleSetAlignContent(v)
+	})
+	return c
+}
+
+// Wrap sets the `flex-wrap: wrap` property.
+func (c *LayoutComponent) Wrap() *LayoutComponent {
+	return c.FlexWrap(flex.WrapWrap)
+}
+
+// FlexWrap sets the `flex-wrap` property.
+func (c *LayoutComponent) FlexWrap(v flex.Wrap) *LayoutComponent {
+	c.builder = c.builder.Raw(func(n *flex.Node) {
+		n.StyleSetFlexWrap(v)
+	})
+	return c
+}
+
+// Grow sets the `flex-grow` property.
+func (c *LayoutComponent) Grow(x float32) *LayoutComponent {
+	c.builder = c.builder.Raw(func(n *flex.Node) {
+		n.StyleSetFlexGrow(x)
+	})
+	return c
+}
+
+// Shrink sets the `flex-shrink` property.
+func (c *LayoutComponent) Shrink(x float32) *LayoutComponent {
+	c.builder = c.builder.Raw(func(n *flex.Node) {
+		n.StyleSetFlexShrink(x)
+	})
+	return c
+}
+
+// Basis sets the `flex-basis` property.
+func (c *LayoutComponent) Basis(x int) *LayoutComponent {
+	c.builder = c.builder.Raw(func(n *flex.Node) {
+		n.StyleSetFlexBasis(float32(x))
+	})
+	return c
+}
+
+// BasisPercent sets the `flex-basis` property to a percentage of the
+// parent, such as 50 for 50%.
+func (c *LayoutComponent) BasisPercent(x float32) *LayoutComponent {
+	c.builder = c.builder.Raw(func(n *flex.Node) {
+		n.StyleSetFlexBasisPercent(x)
+	})
+	return c
+}
+
+// Width sets the `width` property.
+func (c *LayoutComponent) Width(x int) *LayoutComponent {
+	c.builder = c.builder.Raw(func(n *flex.Node) {
+		n.StyleSetWidth(float32(x))
+	})
+	return c
+}
+
+// WidthPercent sets the `width` property to a percentage of the parent,
+// such as 50 for 50%.
+func (c *LayoutComponent) WidthPercent(x float32) *LayoutComponent {
+	c.builder = c.builder.Raw(func(n *flex.Node) {
+		n.StyleSetWidthPercent(x)
+	})
+	return c
+}
+
+// Height sets the `height` property.
+func (c *LayoutComponent) Height(x int) *LayoutComponent {
+	c.builder = c.builder.Raw(func(n *flex.Node) {
+		n.StyleSetHeight(float32(x))
+	})
+	return c
+}
+
+// HeightPercent sets the `height` property to a percentage of the parent,
+// such as 50 for 50%. This only has an effect if the parent has a height.
+func (c *LayoutComponent) HeightPercent(x float32) *LayoutComponent {
+	c.builder = c.builder.Raw(func(n *flex.Node) {
+		n.StyleSetHeightPercent(x)
+	})
+	return c
+}
+
+// MinWidth sets the `min-width` property.
+func (c *LayoutComponent) MinWidth(x int) *LayoutComponent {
+	c.builder = c.builder.Raw(func(n *flex.Node) {
+		n.StyleSetMinWidth(float32(x))
+	})
+	return c
+}
+
+// MaxWidth sets the `max-width` property.
+func (c *LayoutComponent) MaxWidth(x int) *LayoutComponent {
+	c.builder = c.builder.Raw(func(n *flex.Node) {
+		n.StyleSetMaxWidth(float32(x))
+	})
+	return c
+}
+
+// MinHeight sets the `min-height` property.
+func (c *LayoutComponent) MinHeight(x int) *LayoutComponent {
+	c.builder = c.builder.Raw(func(n *flex.Node) {
+		n.StyleSetMinHeight(float32(x))
+	})
+	return c
+}
+
+// MaxHeight sets the `max-height` property.
+func (c *LayoutComponent) MaxHeight(x int) *LayoutComponent {
+	c.builder = c.builder.Raw(func(n *flex.Node) {
+		n.StyleSetMaxHeight(float32(x))
+	})
+	return c
+}
+
+// Absolute sets the `position: absolute` property. The component is
+// removed from the flow of its siblings and positioned relative to its
+// parent using Top, Right, Bottom, and Left. Absolutely positioned
+// components are drawn over siblings with the same z-index and hide
+// anything beneath them. Use ZIndex to draw them beneath other components.
+func (c *LayoutComponent) Absolute() *LayoutComponent {
 	c.builder = c.builder.Raw(func(n *flex.Node) {
-		n.StyleSetPadding(flex.EdgeLeft, float32(x))
+		n.StyleSetPositionType(flex.PositionTypeAbsolute)
 	})
 	return c
 }
 
-// PaddingRight sets the `margin-left` property.
+// Top sets the `top` property.
+func (c *LayoutComponent) Top(x int) *LayoutComponent {
+	return c.position(flex.EdgeTop, x)
+}
+
+// Right sets the `right` property.
+func (c *LayoutComponent) Right(x int) *LayoutComponent {
+	return c.position(flex.EdgeRight, x)
+}
+
+// Bottom sets the `bottom` property.
+func (c *LayoutComponent) Bottom(x int) *LayoutComponent {
+	return c.position(flex.EdgeBottom, x)
+}
+
+// Left sets the `left` property.
+func (c *LayoutComponent) Left(x int) *LayoutComponent {
+	return c.position(flex.EdgeLeft, x)
+}
+
+// ZIndex sets the `z-index` property. Components are drawn in order of
+// their z-index, so a component with a higher z-index is drawn over its
+// siblings. Siblings with the same z-index, which is zero by default, are
+// drawn with absolutely positioned siblings last and otherwise in order,
+// so later siblings are drawn over earlier ones.
+func (c *LayoutComponent) ZIndex(z int) *LayoutComponent {
+	c.z = z
+	return c
+}
+
+// Margin sets the `margin` property for all edges.
+func (c *LayoutComponent) Margin(x int) *LayoutComponent {
+	return c.margin(flex.EdgeAll, x)
+}
+
+// MarginLeft sets the `margin-left` property.
+func (c *LayoutComponent) MarginLeft(x int) *LayoutComponent {
+	return c.margin(flex.EdgeLeft, x)
+}
+
+// MarginRight sets the `margin-right` property.
+func (c *LayoutComponent) MarginRight(x int) *LayoutComponent {
+	return c.margin(flex.EdgeRight, x)
+}
+
+// MarginTop sets the `margin-top` property.
+func (c *LayoutComponent) MarginTop(x int) *LayoutComponent {
+	return c.margin(flex.EdgeTop, x)
+}
+
+// MarginBottom sets the `margin-bottom` property.
+func (c *LayoutComponent) MarginBottom(x int) *LayoutComponent {
+	return c.margin(flex.EdgeBottom, x)
+}
+
+// Padding sets the `padding` property for all edges.
+func (c *LayoutComponent) Padding(x int) *LayoutComponent {
+	return c.padding(flex.EdgeAll, x)
+}
+
+// PaddingLeft sets the `padding-left` property.
+func (c *LayoutComponent) PaddingLeft(x int) *LayoutComponent {
+	return c.padding(flex.EdgeLeft, x)
+}
+
+// PaddingRight sets the `padding-right` property.
 func (c *LayoutComponent) PaddingRight(x int) *LayoutComponent {
+	return c.padding(flex.EdgeRight, x)
+}
+
+// PaddingTop sets the `padding-top` property.
+func (c *LayoutComponent) PaddingTop(x int) *LayoutComponent {
+	return c.padding(flex.EdgeTop, x)
+}
+
+// PaddingBottom sets the `padding-bottom` property.
+func (c *LayoutComponent) PaddingBottom(x int) *LayoutComponent {
+	return c.padding(flex.EdgeBottom, x)
+}
+
+func (c *LayoutComponent) position(edge flex.Edge, x int) *LayoutComponent {
 	c.builder = c.builder.Raw(func(n *flex.Node) {
-		n.StyleSetPadding(flex.EdgeRight, float32(x))
+		n.StyleSetPosition(edge, float32(x))
+	})
+	return c
+}
+
+func (c *LayoutComponent) margin(edge flex.Edge, x int) *LayoutComponent {
+	c.builder = c.builder.Raw(func(n *flex.Node) {
+		n.StyleSetMargin(edge, float32(x))
+	})
+	return c
+}
+
+func (c *LayoutComponent) padding(edge flex.Edge, x int) *LayoutComponent {
+	c.builder = c.builder.Raw(func(n *flex.Node) {
+		n.StyleSetPadding(edge, float32(x))
 	})
 	return c
 }
@@ -66,6 +312,13 @@ func (c *LayoutComponent) Body(context.Context) Component {
 	return Fragment(c.inner...)
 }
 
+// Dirty implements ComponentDirtier. Changing the layout after the
+// component has been rendered is not detected, so the layout should be
+// fully configured before the component is added to a document.
+func (c *LayoutComponent) Dirty(context.Context) bool {
+	return false
+}
+
 // componentLayout internal implementation.
 func (c *LayoutComponent) Layout() *layout.Builder {
 	return c.builder
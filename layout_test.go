@@ -1,9 +1,12 @@
 package glint
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/mitchellh/go-glint/flex"
 )
 
 func TestLayout(t *testing.T) {
@@ -42,3 +45,270 @@ func TestLayout(t *testing.T) {
 		))
 	})
 }
+
+func TestLayout_builder(t *testing.T) {
+	cases := []struct {
+		Name     string
+		C        Component
+		Expected string
+	}{
+		{
+			"row",
+			Layout(Text("a"), Text("b")).Row(),
+			"ab",
+		},
+		{
+			"row reverse",
+			Layout(Text("a"), Text("b")).RowReverse(),
+			strings.Repeat(" ", 78) + "ba",
+		},
+		{
+			"column reverse",
+			Layout(Text("a"), Text("b")).ColumnReverse(),
+			"b\na",
+		},
+		{
+			"justify end",
+			Layout(Text("status")).Row().JustifyContent(flex.JustifyFlexEnd),
+			strings.Repeat(" ", 74) + "status",
+		},
+		{
+			"justify center",
+			Layout(Text("ab")).Row().Width(10).JustifyContent(flex.JustifyCenter),
+			"    ab",
+		},
+		{
+			"justify space between",
+			Layout(Text("a"), Text("b"), Text("c")).Row().Width(5).JustifyContent(flex.JustifySpaceBetween),
+			"a b c",
+		},
+		{
+			"align items end",
+			Layout(Text("ab")).Width(10).AlignItems(flex.AlignFlexEnd),
+			"        ab",
+		},
+		{
+			"align self",
+			Layout(
+				Layout(Text("a")).AlignSelf(flex.AlignCenter),
+				Text("b"),
+			).Width(5).AlignItems(flex.AlignFlexStart),
+			"  a\nb",
+		},
+		{
+			"right aligned status column",
+			Layout(
+				Text("name"),
+				Layout(Text("ok")).Row().Grow(1).JustifyContent(flex.JustifyFlexEnd),
+			).Row().Width(10),
+			"name    ok",
+		},
+		{
+			"grow",
+			Layout(Layout(Text("a")).Grow(1), Text("b")).Row().Width(5),
+			"a   b",
+		},
+		{
+			"shrink",
+			Layout(Layout(Text("abcd")).Shrink(1), Layout(Text("ef")).Shrink(0)).Row().Width(4),
+			"abef",
+		},
+		{
+			"basis",
+			Layout(Layout(Text("a")).Basis(3), Text("b")).Row(),
+			"a  b",
+		},
+		{
+			"basis percent",
+			Layout(Layout(Text("a")).BasisPercent(50), Text("b")).Row().Width(10),
+			"a    b",
+		},
+		{
+			"width",
+			Layout(Layout(Text("a")).Width(3), Text("b")).Row(),
+			"a  b",
+		},
+		{
+			"width percent",
+			Layout(Layout(Text("a")).WidthPercent(50), Text("b")).Row().Width(10),
+			"a    b",
+		},
+		{
+			"width wraps text",
+			Layout(Text("hello world")).Width(5),
+			"hello\nworld",
+		},
+		{
+			"min width",
+			Layout(Layout(Text("a")).MinWidth(3), Text("b")).Row(),
+			"a  b",
+		},
+		{
+			"max width",
+			Layout(Layout(Text("abc")).MaxWidth(2), Text("d")).Row(),
+			"abd",
+		},
+		{
+			"wrap",
+			Layout(Text("aaa"), Text("bbb"), Text("ccc")).Row().Width(7).Wrap(),
+			"aaabbb\nccc",
+		},
+		{
+			"margin",
+			Layout(Text("a")).Margin(1),
+			"\n a \n",
+		},
+		{
+			"padding",
+			Layout(Text("a")).Padding(1),
+			"\n a \n",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			require.Equal(t, tt.Expected, TestRender(t, tt.C))
+		})
+	}
+}
+
+func TestLayout_vertical(t *testing.T) {
+	cases := []struct {
+		Name     string
+		C        Component
+		Expected string
+	}{
+		{
+			"top margin",
+			Layout(Text("a")).MarginTop(2),
+			"\n\na",
+		},
+		{
+			"bottom margin",
+			Fragment(Layout(Text("a")).MarginBottom(1), Text("b")),
+			"a\n\nb",
+		},
+		{
+			"top and bottom padding",
+			Fragment(Layout(Text("a")).PaddingTop(1).PaddingBottom(2), Text("b")),
+			"\na\n\n\nb",
+		},
+		{
+			"stacked",
+			Fragment(
+				Layout(Text("one")).MarginBottom(1),
+				Layout(Text("two")).MarginBottom(1),
+				Text("three"),
+			),
+			"one\n\ntwo\n\nthree",
+		},
+		{
+			"nested",
+			Layout(
+				Layout(Text("inner")).MarginTop(1).PaddingLeft(2),
+				Text("after"),
+			).PaddingTop(1).PaddingBottom(1),
+			"\n\n  inner\nafter\n",
+		},
+		{
+			"trailing margin",
+			Layout(Text("a")).MarginBottom(2),
+			"a\n\n",
+		},
+		{
+			"fixed height",
+			Fragment(Layout(Text("a")).Height(3), Text("b")),
+			"a\n\n\nb",
+		},
+		{
+			"justify center in column",
+			Fragment(Layout(Text("a")).Height(3).JustifyContent(flex.JustifyCenter), Text("b")),
+			"\na\n\nb",
+		},
+		{
+			"row with vertical margin",
+			Layout(Text("a"), Layout(Text("b")).MarginTop(1)).Row(),
+			"a\n b",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			require.Equal(t, tt.Expected, TestRender(t, tt.C))
+		})
+	}
+}
+
+func TestLayout_absolute(t *testing.T) {
+	cases := []struct {
+		Name     string
+		C        Component
+		Expected string
+	}{
+		{
+			"badge top right",
+			Layout(
+				Text("status\nline two"),
+				Layout(Text("[1]")).Absolute().Top(0).Right(0),
+			).Width(12),
+			"status   [1]\nline two",
+		},
+		{
+			"out of flow",
+			Layout(
+				Layout(Text("x")).Absolute().Left(3),
+				Text("a"),
+				Text("b"),
+			),
+			"a  x\nb",
+		},
+		{
+			"overlay hides content",
+			Layout(
+				Text("aaaaaa\nbbbbbb\ncccccc"),
+				Layout(Text("hi")).Absolute().Top(1).Left(1).Width(4),
+			),
+			"aaaaaa\nbhi  b\ncccccc",
+		},
+		{
+			"overlay before content",
+			Layout(
+				Layout(Text("x")).Absolute(),
+				Text("abc"),
+			),
+			"xbc",
+		},
+		{
+			"later siblings drawn over earlier",
+			Layout(
+				Text("abc"),
+				Layout(Text("one")).Absolute(),
+				Layout(Text("2")).Absolute(),
+			),
+			"2ne",
+		},
+		{
+			"z index",
+			Layout(
+				Text("abc"),
+				Layout(Text("2")).Absolute().ZIndex(1),
+				Layout(Text("one")).Absolute(),
+			),
+			"2ne",
+		},
+		{
+			"negative z index",
+			Layout(
+				Layout(Text("one")).Absolute().ZIndex(-1),
+				Text("x"),
+			),
+			"xne",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			require.Equal(t, tt.Expected, TestRender(t, tt.C))
+		})
+	}
+}
@@ -18,6 +18,11 @@ type TextNodeContext struct {
 	// The context at the time layout was done
 	Context context.Context
 
+	// Style is the resolved style for this text from any Style components
+	// that this text is nested within. Renderers can use this to style
+	// the text in a way that makes sense for their medium.
+	Style TextStyle
+
 	// Text is the rendered text. This is populated after MeasureTextNode
 	// is called. Note that this may not fit in the final layout calculations
 	// since it is populated on measurement.
@@ -27,6 +32,13 @@ type TextNodeContext struct {
 	// if the text above fits in the final size. Text is guaranteed to fit
 	// in this size.
 	Size flex.Size
+
+	// key is the key of the component, if any. measured is true if the
+	// node was measured since it was last built and layout is the size of
+	// the node in the last layout. These are used to reconcile the tree.
+	key      interface{}
+	measured bool
+	layout   flex.Size
 }
 
 func (c *TextNodeContext) Component() Component { return c.C }
@@ -52,6 +64,7 @@ func MeasureTextNode(
 	}
 
 	// Otherwise, we have to render this.
+	ctx.measured = true
 	ctx.Text = ctx.C.Render(uint(height), uint(width))
 
 	// Word wrap and truncate if we're beyond the width limit.
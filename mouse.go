@@ -0,0 +1,220 @@
+package glint
+
+import (
+	"context"
+	"io"
+
+	"github.com/mitchellh/go-glint/flex"
+)
+
+// MouseButton is the button of a MouseEvent.
+type MouseButton int
+
+const (
+	// MouseNone is used for motion events without a button held.
+	MouseNone MouseButton = iota
+	MouseLeft
+	MouseMiddle
+	MouseRight
+	MouseWheelUp
+	MouseWheelDown
+)
+
+// MouseAction is the action of a MouseEvent.
+type MouseAction int
+
+const (
+	MousePress MouseAction = iota
+	MouseRelease
+
+	// MouseMotion is sent when the mouse moves while a button is held,
+	// such as when dragging.
+	MouseMotion
+)
+
+// MouseEvent is a mouse event read from the input. See Document.EnableMouse.
+type MouseEvent struct {
+	Button MouseButton
+	Action MouseAction
+	Mod    KeyMod
+
+	// X and Y are the zero-based column and row of the event relative to
+	// the top-left corner of the component handling the event.
+	X, Y int
+
+	// RootX and RootY are the zero-based column and row of the event
+	// relative to the top-left corner of the rendered output.
+	RootX, RootY int
+}
+
+// ComponentMouseHandler allows components to handle mouse events when the
+// mouse is enabled on a Document (see Document.EnableMouse).
+type ComponentMouseHandler interface {
+	Component
+
+	// HandleMouse is called for mouse events over the component. Events
+	// are given to the deepest component under the mouse first and then
+	// bubble up through its parents until a component returns true.
+	//
+	// Like HandleKey, this is called outside of the render loop and the
+	// document is invalidated after each event.
+	HandleMouse(ctx context.Context, ev MouseEvent) bool
+}
+
+// EnableMouse enables mouse reporting for clicks, the mouse wheel, and
+// dragging. Mouse events are read with the key events after EnableInput
+// and are given to components that implement ComponentMouseHandler. Mouse
+// reporting is disabled on Close. Pressing the left button over a
+// focusable component (see ComponentFocusable) focuses it.
+//
+// This only has an effect if the input is a terminal and the renderer
+// draws to a terminal. Mouse events are matched against the layout of
+// the last frame, which requires knowing where the output is on the
+// screen. For TerminalRenderer, this is only known in Fullscreen mode, so
+// mouse reporting isn't enabled otherwise and the terminal keeps its usual
+// mouse behavior, such as selecting text.
+func (d *Document) EnableMouse() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.mouse {
+		return
+	}
+
+	d.mouse = true
+	if d.input != nil && d.input.out != nil && mouseOriginKnown(d.r) {
+		io.WriteString(d.input.out, mouseEnable)
+		d.input.mouse = true
+	}
+}
+
+// dispatchMouse gives the event to the mouse handlers under the mouse.
+// This returns false if the document is closed.
+func (d *Document) dispatchMouse(ev MouseEvent) bool {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return false
+	}
+
+	// Determine the position of our output on the screen. If we can't
+	// then we ignore the event.
+	root := d.prevRoot
+	if r, ok := d.r.(mouseOriginer); ok {
+		x, y, ok := r.mouseOrigin()
+		if !ok {
+			root = nil
+		}
+
+		ev.RootX -= x
+		ev.RootY -= y
+	}
+
+	var path []*flex.Node
+	if root != nil {
+		path = hitTest(nil, root, ev.RootX, ev.RootY)
+	}
+	ctx := d.context()
+
+	// Clicking a focusable component focuses it.
+	if ev.Button == MouseLeft && ev.Action == MousePress {
+		for i := len(path) - 1; i >= 0; i-- {
+			pctx, ok := path[i].Context.(*parentContext)
+			if !ok {
+				continue
+			}
+
+			if f, ok := pctx.C.(ComponentFocusable); ok && f.Focusable() {
+				d.focused = pctx
+				d.focusPending = nil
+				break
+			}
+		}
+	}
+
+	var focused Component
+	if d.focused != nil {
+		focused = d.focused.C
+	}
+	d.mu.Unlock()
+
+	// Bubble from the deepest node up.
+	for i := len(path) - 1; i >= 0; i-- {
+		node := path[i]
+		tctx := node.Context.(treeContext)
+		h, ok := tctx.Component().(ComponentMouseHandler)
+		if !ok {
+			continue
+		}
+
+		x, y := nodePosition(node)
+		local := ev
+		local.X = ev.RootX - x
+		local.Y = ev.RootY - y
+		hctx := context.WithValue(ctx, isFocusedCtxKey, sameComponent(h, focused))
+		if h.HandleMouse(hctx, local) {
+			break
+		}
+	}
+
+	return true
+}
+
+// hitTest appends the path of component nodes to the deepest node under
+// the given position relative to parent, which is a node from the last
+// render.
+func hitTest(path []*flex.Node, parent *flex.Node, x, y int) []*flex.Node {
+	// Children drawn last are on top so we check them first.
+	children := paintOrder(parent)
+	for i := len(children) - 1; i >= 0; i-- {
+		child := children[i]
+		left := int(child.LayoutGetLeft())
+		top := int(child.LayoutGetTop())
+		if x < left || y < top ||
+			x >= left+int(child.LayoutGetWidth()) ||
+			y >= top+int(child.LayoutGetHeight()) {
+			continue
+		}
+
+		if _, ok := child.Context.(treeContext); ok {
+			path = append(path, child)
+		}
+
+		return hitTest(path, child, x-left, y-top)
+	}
+
+	return path
+}
+
+// nodePosition returns the position of the node relative to the root.
+func nodePosition(node *flex.Node) (x, y int) {
+	for n := node; n.Parent != nil; n = n.Parent {
+		x += int(n.LayoutGetLeft())
+		y += int(n.LayoutGetTop())
+	}
+
+	return x, y
+}
+
+// mouseOriginer is implemented by renderers that know where the top-left
+// corner of their output is on the screen. If a renderer doesn't implement
+// this, mouse positions are relative to the output. If ok is false then
+// the position is unknown and mouse events are ignored.
+type mouseOriginer interface {
+	mouseOrigin() (x, y int, ok bool)
+}
+
+// mouseOriginKnown returns true if mouse events can be matched against the
+// output of r.
+func mouseOriginKnown(r Renderer) bool {
+	if r, ok := r.(mouseOriginer); ok {
+		_, _, ok := r.mouseOrigin()
+		return ok
+	}
+
+	return true
+}
+
+const (
+	mouseEnable  = "\x1b[?1000h\x1b[?1002h\x1b[?1006h"
+	mouseDisable = "\x1b[?1006l\x1b[?1002l\x1b[?1000l"
+)
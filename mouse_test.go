@@ -0,0 +1,135 @@
+package glint
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTestDriver_mouse(t *testing.T) {
+	require := require.New(t)
+
+	one := &testMouseHandler{text: "one", focusable: true}
+	two := &testMouseHandler{text: "two", handle: true}
+	outer := &testMouseHandler{inner: Fragment(one, two)}
+	d := NewTestDriver(t, outer)
+	require.Equal("one\ntwo", d.Step(0))
+
+	// Events bubble up from the component under the mouse with
+	// positions relative to each component.
+	d.Input("\x1b[<0;2;1M")
+	require.Equal([]MouseEvent{
+		{Button: MouseLeft, Action: MousePress, X: 1, Y: 0, RootX: 1, RootY: 0},
+	}, one.Events())
+	require.Equal([]MouseEvent{
+		{Button: MouseLeft, Action: MousePress, X: 1, Y: 0, RootX: 1, RootY: 0},
+	}, outer.Events())
+
+	// Clicking a focusable component focuses it.
+	require.Equal(one, d.Document.Focused())
+
+	// A handled event stops.
+	d.Input("\x1b[<65;3;2M")
+	require.Equal([]MouseEvent{
+		{Button: MouseWheelDown, Action: MousePress, X: 2, Y: 0, RootX: 2, RootY: 1},
+	}, two.Events())
+	require.Len(outer.Events(), 1)
+
+	// Clicking a component that isn't focusable doesn't change focus.
+	d.Input("\x1b[<0;1;2M")
+	require.Equal(one, d.Document.Focused())
+
+	// Events outside of any component are ignored.
+	d.Input("\x1b[<0;1;10M")
+	require.Len(one.Events(), 1)
+	require.Len(outer.Events(), 1)
+}
+
+func TestTestDriver_mouseOverlay(t *testing.T) {
+	require := require.New(t)
+
+	under := &testMouseHandler{text: "under"}
+	over := &testMouseHandler{text: "over", handle: true}
+	d := NewTestDriver(t, Layout(
+		Layout(over).Absolute().Left(1).ZIndex(1),
+		under,
+	))
+	require.Equal("uover", d.Step(0))
+
+	// The overlay is on top so it receives the event.
+	d.Input("\x1b[<0;3;1M")
+	require.Len(over.Events(), 1)
+	require.Empty(under.Events())
+
+	d.Input("\x1b[<0;1;1M")
+	require.Len(over.Events(), 1)
+	require.Len(under.Events(), 1)
+}
+
+func TestTerminalRenderer_mouseOrigin(t *testing.T) {
+	require := require.New(t)
+
+	// The position of inline output isn't known.
+	r := &TerminalRenderer{}
+	_, _, ok := r.mouseOrigin()
+	require.False(ok)
+	r.Fullscreen = true
+	_, _, ok = r.mouseOrigin()
+	require.True(ok)
+}
+
+func TestDocument_enableMouseOrigin(t *testing.T) {
+	require := require.New(t)
+
+	// Mouse reporting isn't enabled if we don't know where the output is.
+	var buf bytes.Buffer
+	r := &TerminalRenderer{Output: &buf}
+	d := New()
+	d.SetRenderer(r)
+	d.input = &documentInput{out: &buf, doneCh: make(chan struct{})}
+	d.EnableMouse()
+	require.Empty(buf.String())
+	require.False(d.input.mouse)
+
+	r.Fullscreen = true
+	d.mouse = false
+	d.EnableMouse()
+	require.Equal(mouseEnable, buf.String())
+	require.True(d.input.mouse)
+}
+
+type testMouseHandler struct {
+	sync.Mutex
+
+	inner     Component
+	text      string
+	handle    bool
+	focusable bool
+	events    []MouseEvent
+}
+
+func (c *testMouseHandler) Body(context.Context) Component {
+	if c.inner != nil {
+		return c.inner
+	}
+
+	return Text(c.text)
+}
+
+func (c *testMouseHandler) Focusable() bool { return c.focusable }
+
+func (c *testMouseHandler) HandleMouse(ctx context.Context, ev MouseEvent) bool {
+	c.Lock()
+	defer c.Unlock()
+	c.events = append(c.events, ev)
+	return c.handle
+}
+
+func (c *testMouseHandler) Events() []MouseEvent {
+	c.Lock()
+	defer c.Unlock()
+	return append([]MouseEvent(nil), c.events...)
+}
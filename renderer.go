@@ -30,10 +30,42 @@ type Renderer interface {
 	// prev will be the previous root that was rendered. This can be used to
 	// determine layout differences. This will be nil if this is the first
 	// render. If the height of the previous node is zero then that means that
-	// everything drawn was finalized.
+	// everything drawn was finalized. The prev tree is a copy of the layout
+	// of the previous frame, but the node contexts are shared with the
+	// current frame for components that were reused.
 	RenderRoot(root, prev *flex.Node)
 }
 
+// RendererRefresher is an optional interface that a Renderer can implement
+// to request that a frame be rendered outside of the regular refresh rate.
+// For example, TerminalRenderer uses this to redraw as soon as the
+// terminal is resized.
+type RendererRefresher interface {
+	Renderer
+
+	// Refresh returns a channel that receives a value whenever the renderer
+	// would like a new frame to be rendered.
+	Refresh() <-chan struct{}
+}
+
+// frameRenderer is implemented by renderers that need information about
+// the frame from the document. beginFrame is called before each call to
+// RenderRoot.
+type frameRenderer interface {
+	beginFrame(frameInfo)
+}
+
+// frameInfo is the information given to a frameRenderer.
+type frameInfo struct {
+	// finalized is the number of children at the front of the root that
+	// are finalized. These are pruned from the document after the frame is
+	// rendered and won't be given to the renderer again.
+	finalized int
+
+	// clock is the clock of the document. This is never nil.
+	clock Clock
+}
+
 // WithRenderer inserts the renderer into the context. This is done automatically
 // by Document for components.
 func WithRenderer(ctx context.Context, r Renderer) context.Context {
@@ -0,0 +1,141 @@
+package glint
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/mitchellh/go-glint/flex"
+)
+
+// CastRenderer records the output of a TerminalRenderer to an asciinema
+// v2 ".cast" file. This can be used to capture demos or bug reproductions
+// which can then be played back with asciinema.
+//
+// The output is still written to the Output of the TerminalRenderer. To
+// only record, set the TerminalRenderer Output to ioutil.Discard along with
+// a fixed Rows and Cols.
+type CastRenderer struct {
+	// Renderer is the TerminalRenderer that does the actual rendering.
+	// Everything this renderer writes to its Output is recorded.
+	Renderer *TerminalRenderer
+
+	// Cast is where the recording is written to in the asciicast v2 format.
+	Cast io.Writer
+
+	// Clock is used for the timestamps in the recording. This can be set
+	// to a fake clock, such as TestClock, for reproducible recordings. If
+	// this is nil, the system clock is used.
+	Clock Clock
+
+	start      time.Time
+	rows, cols uint
+}
+
+func (r *CastRenderer) LayoutRoot() *flex.Node {
+	return r.Renderer.LayoutRoot()
+}
+
+func (r *CastRenderer) RenderRoot(root, prev *flex.Node) {
+	rootCtx := root.Context.(*termRootContext)
+
+	// The first frame writes our header with the initial size. After that,
+	// we record any changes in size as resize events.
+	if r.start.IsZero() {
+		r.writeHeader(rootCtx.Rows, rootCtx.Cols)
+	} else if r.rows != rootCtx.Rows || r.cols != rootCtx.Cols {
+		r.rows, r.cols = rootCtx.Rows, rootCtx.Cols
+		r.writeEvent("r", fmt.Sprintf("%dx%d", r.cols, r.rows))
+	}
+
+	r.record(func() { r.Renderer.RenderRoot(root, prev) })
+}
+
+// Refresh implements RendererRefresher.
+func (r *CastRenderer) Refresh() <-chan struct{} {
+	return r.Renderer.Refresh()
+}
+
+func (r *CastRenderer) mouseOrigin() (x, y int, ok bool) {
+	return r.Renderer.mouseOrigin()
+}
+
+func (r *CastRenderer) terminalOutput() io.Writer {
+	return r.Renderer.terminalOutput()
+}
+
+func (r *CastRenderer) Close() error {
+	// If nothing was rendered, we still write the header so that the
+	// recording is valid.
+	if r.start.IsZero() {
+		rows, cols := r.Renderer.Rows, r.Renderer.Cols
+		if rows == 0 || cols == 0 {
+			rows, cols = r.Renderer.size()
+		}
+
+		r.writeHeader(rows, cols)
+	}
+
+	var err error
+	r.record(func() { err = r.Renderer.Close() })
+	return err
+}
+
+// record calls f and records anything the terminal renderer writes to its
+// output while it is called as a single output event.
+func (r *CastRenderer) record(f func()) {
+	var buf bytes.Buffer
+	output := r.Renderer.Output
+	r.Renderer.Output = io.MultiWriter(output, &buf)
+	defer func() {
+		r.Renderer.Output = output
+
+		if buf.Len() > 0 {
+			r.writeEvent("o", buf.String())
+		}
+	}()
+
+	f()
+}
+
+// writeHeader writes the header with the initial size and starts the
+// recording.
+func (r *CastRenderer) writeHeader(rows, cols uint) {
+	r.start = r.clock().Now()
+	r.rows, r.cols = rows, cols
+	header, err := json.Marshal(map[string]interface{}{
+		"version":   2,
+		"width":     r.cols,
+		"height":    r.rows,
+		"timestamp": r.start.Unix(),
+	})
+	if err != nil {
+		// This should never happen since we control all the values.
+		panic(err)
+	}
+
+	r.Cast.Write(append(header, '\n'))
+}
+
+func (r *CastRenderer) writeEvent(code, data string) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		// This should never happen since we're encoding a string.
+		panic(err)
+	}
+
+	elapsed := r.clock().Now().Sub(r.start).Seconds()
+	fmt.Fprintf(r.Cast, "[%s, %q, %s]\n",
+		strconv.FormatFloat(elapsed, 'f', 6, 64), code, encoded)
+}
+
+func (r *CastRenderer) clock() Clock {
+	if r.Clock == nil {
+		return systemClock{}
+	}
+
+	return r.Clock
+}
@@ -0,0 +1,107 @@
+package glint
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCastRenderer(t *testing.T) {
+	require := require.New(t)
+
+	var value string
+	var out, cast bytes.Buffer
+	term := &TerminalRenderer{Output: &out}
+	term.Resize(5, 20)
+
+	d := New()
+	d.SetRenderer(&CastRenderer{Renderer: term, Cast: &cast})
+	d.Append(TextFunc(func(rows, cols uint) string { return value }))
+
+	value = "hello"
+	d.RenderFrame()
+	value = "help"
+	d.RenderFrame()
+	term.Resize(5, 10)
+	value = "hi"
+	d.RenderFrame()
+	require.NoError(d.Close())
+
+	lines := strings.Split(strings.TrimSpace(cast.String()), "\n")
+
+	// Verify the header
+	var header map[string]interface{}
+	require.NoError(json.Unmarshal([]byte(lines[0]), &header))
+	require.Equal(float64(2), header["version"])
+	require.Equal(float64(20), header["width"])
+	require.Equal(float64(5), header["height"])
+
+	// Verify our events
+	var events []string
+	var output strings.Builder
+	for _, line := range lines[1:] {
+		var event []interface{}
+		require.NoError(json.Unmarshal([]byte(line), &event))
+		require.Len(event, 3)
+		require.IsType(float64(0), event[0])
+		events = append(events, event[1].(string))
+		if event[1] == "o" {
+			output.WriteString(event[2].(string))
+		} else {
+			require.Equal("10x5", event[2])
+		}
+	}
+	require.Equal([]string{"o", "o", "r", "o", "o"}, events)
+
+	// Everything written to the terminal was recorded
+	require.Equal(out.String(), output.String())
+}
+
+func TestCastRenderer_clock(t *testing.T) {
+	require := require.New(t)
+
+	var cast bytes.Buffer
+	clock := NewTestClock(time.Time{})
+	d := New()
+	d.SetRenderer(&CastRenderer{
+		Renderer: &TerminalRenderer{Output: ioutil.Discard, Rows: 5, Cols: 20},
+		Cast:     &cast,
+		Clock:    clock,
+	})
+	d.Append(Text("hello"))
+
+	d.RenderFrame()
+	clock.Advance(1500 * time.Millisecond)
+	require.NoError(d.Close())
+
+	// The recording is reproducible with a fake clock
+	require.Equal(strings.Join([]string{
+		`{"height":5,"timestamp":1577836800,"version":2,"width":20}`,
+		`[0.000000, "o", "hello"]`,
+		`[1.500000, "o", "\n"]`,
+		``,
+	}, "\n"), cast.String())
+}
+
+func TestCastRenderer_noRender(t *testing.T) {
+	require := require.New(t)
+
+	// A recording that never rendered still has a header
+	var cast bytes.Buffer
+	r := &CastRenderer{
+		Renderer: &TerminalRenderer{Output: ioutil.Discard, Rows: 5, Cols: 20},
+		Cast:     &cast,
+		Clock:    NewTestClock(time.Time{}),
+	}
+	require.NoError(r.Close())
+	require.Equal(strings.Join([]string{
+		`{"height":5,"timestamp":1577836800,"version":2,"width":20}`,
+		`[0.000000, "o", "\n"]`,
+		``,
+	}, "\n"), cast.String())
+}
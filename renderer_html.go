@@ -0,0 +1,196 @@
+package glint
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/mitchellh/go-glint/flex"
+)
+
+// HTMLRenderer renders output as HTML to a string builder. This will clear
+// the builder on each frame render.
+//
+// The output is a single container element with each text node drawn as
+// an absolutely positioned block using the computed layout, so the output
+// is laid out the same as it would be in a terminal. Text is sized using
+// the "ch" unit for columns and a fixed line height for rows, and text is
+// clipped to the size of its node. Styles are rendered as inline CSS. The
+// output is deterministic for the same component tree.
+//
+// Absolutely positioned components hide anything beneath them, like they
+// do in a terminal, by drawing a block with the Background color under
+// them.
+type HTMLRenderer struct {
+	// Builder is the strings builder to write to. If this is nil then
+	// it will be created on first render.
+	Builder *strings.Builder
+
+	// Width is a fixed width to set for the root node. If this isn't
+	// set then a width of 80 is arbitrarily used.
+	Width uint
+
+	// Background is the CSS color drawn beneath absolutely positioned
+	// components to hide what is beneath them. This should match the
+	// background of the page. If this isn't set then "Canvas", the
+	// default background color of the browser, is used.
+	Background string
+}
+
+func (r *HTMLRenderer) LayoutRoot() *flex.Node {
+	width := r.Width
+	if width == 0 {
+		width = 80
+	}
+
+	node := flex.NewNode()
+	node.StyleSetWidth(float32(width))
+	return node
+}
+
+func (r *HTMLRenderer) RenderRoot(root, prev *flex.Node) {
+	if r.Builder == nil {
+		r.Builder = &strings.Builder{}
+	}
+
+	// Reset our builder
+	r.Builder.Reset()
+
+	fmt.Fprintf(r.Builder,
+		`<div class="glint" style="position:relative;width:%dch;height:%s;`+
+			`font-family:monospace;white-space:pre;line-height:%s">`+"\n",
+		int(root.LayoutGetWidth()),
+		htmlRows(int(root.LayoutGetHeight())),
+		htmlRows(1))
+	r.renderTree(r.Builder, root, 0, 0)
+	r.Builder.WriteString("</div>\n")
+}
+
+func (r *HTMLRenderer) renderTree(w io.Writer, parent *flex.Node, left, top int) {
+	// Later elements are drawn over earlier elements, so we write them in
+	// the order they're drawn.
+	for _, child := range paintOrder(parent) {
+		// Ignore children with a zero height
+		if child.LayoutGetHeight() == 0 {
+			continue
+		}
+
+		childLeft := left + int(child.LayoutGetLeft())
+		childTop := top + int(child.LayoutGetTop())
+
+		// If we're not a text node then we're a container and we
+		// render our children.
+		ctx, ok := child.Context.(*TextNodeContext)
+		if !ok {
+			// Absolutely positioned containers are overlays, so they
+			// hide anything that was drawn beneath them.
+			if child.Style.PositionType == flex.PositionTypeAbsolute {
+				background := r.Background
+				if background == "" {
+					background = "Canvas"
+				}
+
+				fmt.Fprintf(w, `<div style="position:absolute;left:%dch;top:%s;width:%dch;height:%s;background-color:%s"></div>`+"\n",
+					childLeft, htmlRows(childTop),
+					int(child.LayoutGetWidth()), htmlRows(int(child.LayoutGetHeight())),
+					html.EscapeString(background))
+			}
+
+			r.renderBorder(w, child, childLeft, childTop)
+			r.renderTree(w, child, childLeft, childTop)
+			continue
+		}
+
+		r.renderText(w, ctx.Text, ctx.Style, childLeft, childTop,
+			int(child.LayoutGetWidth()), int(child.LayoutGetHeight()))
+	}
+}
+
+// renderBorder renders the border of the node if it is a box. See Box.
+// Each continuous run of border characters in a row is rendered as text.
+func (r *HTMLRenderer) renderBorder(w io.Writer, node *flex.Node, left, top int) {
+	ctx, ok := node.Context.(*parentContext)
+	if !ok {
+		return
+	}
+
+	box, ok := ctx.C.(*BoxComponent)
+	if !ok {
+		return
+	}
+
+	width := int(node.LayoutGetWidth())
+	height := int(node.LayoutGetHeight())
+	grid := make([][]rune, height)
+	for y := range grid {
+		grid[y] = make([]rune, width)
+	}
+	box.drawBorder(width, height, func(x, y int, r rune) {
+		grid[y][x] = r
+	})
+
+	for y, row := range grid {
+		for x := 0; x < len(row); {
+			if row[x] == 0 {
+				x++
+				continue
+			}
+
+			start := x
+			for x < len(row) && row[x] != 0 {
+				x++
+			}
+
+			r.renderText(w, string(row[start:x]), ctx.style, left+start, top+y, x-start, 1)
+		}
+	}
+}
+
+// renderText renders text at the given position, clipped to the given size.
+func (r *HTMLRenderer) renderText(w io.Writer, text string, style TextStyle, left, top, width, height int) {
+	text = html.EscapeString(text)
+	if css := htmlStyle(style); css != "" {
+		text = fmt.Sprintf(`<span style="%s">%s</span>`, css, text)
+	}
+
+	fmt.Fprintf(w, `<div style="position:absolute;left:%dch;top:%s;width:%dch;height:%s;overflow:hidden">%s</div>`+"\n",
+		left, htmlRows(top), width, htmlRows(height), text)
+}
+
+// htmlRows returns the CSS length for the given number of rows.
+func htmlRows(n int) string {
+	return strconv.FormatFloat(float64(n*htmlLineHeight)/10, 'f', -1, 64) + "em"
+}
+
+// htmlStyle returns the inline CSS for the given style.
+func htmlStyle(s TextStyle) string {
+	var parts []string
+	if s.Foreground.Set {
+		parts = append(parts, "color:"+htmlColor(s.Foreground))
+	}
+	if s.Background.Set {
+		parts = append(parts, "background-color:"+htmlColor(s.Background))
+	}
+	if s.Bold {
+		parts = append(parts, "font-weight:bold")
+	}
+	if s.Italic {
+		parts = append(parts, "font-style:italic")
+	}
+	if s.Underline {
+		parts = append(parts, "text-decoration:underline")
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// htmlColor returns the CSS color value for the given color.
+func htmlColor(c StyleColor) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// htmlLineHeight is the height of a single row in tenths of an em. This
+// is an integer to avoid floating point error in the output.
+const htmlLineHeight = 12
@@ -0,0 +1,64 @@
+package glint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTMLRenderer(t *testing.T) {
+	require := require.New(t)
+
+	render := func() string {
+		r := &HTMLRenderer{Width: 20}
+		d := New()
+		d.SetRenderer(r)
+		d.Append(
+			Text("hello <world>"),
+			Layout(
+				Style(Text("ok"), Color("green"), Bold()),
+				Layout(Style(Text("a\nb"), BGColorRGB(1, 2, 3), Italic(), Underline())).MarginLeft(1),
+			).Row(),
+		)
+
+		d.RenderFrame()
+		return r.Builder.String()
+	}
+
+	first := render()
+	require.Equal(`<div class="glint" style="position:relative;width:20ch;height:3.6em;font-family:monospace;white-space:pre;line-height:1.2em">
+<div style="position:absolute;left:0ch;top:0em;width:20ch;height:1.2em;overflow:hidden">hello &lt;world&gt;</div>
+<div style="position:absolute;left:0ch;top:1.2em;width:2ch;height:1.2em;overflow:hidden"><span style="color:#00aa00;font-weight:bold">ok</span></div>
+<div style="position:absolute;left:3ch;top:1.2em;width:1ch;height:2.4em;overflow:hidden"><span style="background-color:#010203;font-style:italic;text-decoration:underline">a
+b</span></div>
+</div>
+`, first)
+
+	// Rendering is deterministic
+	for i := 0; i < 10; i++ {
+		require.Equal(first, render())
+	}
+}
+
+func TestHTMLRenderer_overlay(t *testing.T) {
+	require := require.New(t)
+
+	r := &HTMLRenderer{Width: 6, Background: "#000"}
+	d := New()
+	d.SetRenderer(r)
+	d.Append(Layout(
+		Text("aaaaaa\nbbbbbb"),
+		Layout(Text("hi")).Absolute().Top(1).Left(1).Width(4),
+	))
+
+	// The overlay is drawn over a background that hides the text beneath
+	// it, the same as the string output "aaaaaa\nbhi  b".
+	d.RenderFrame()
+	require.Equal(`<div class="glint" style="position:relative;width:6ch;height:2.4em;font-family:monospace;white-space:pre;line-height:1.2em">
+<div style="position:absolute;left:0ch;top:0em;width:6ch;height:2.4em;overflow:hidden">aaaaaa
+bbbbbb</div>
+<div style="position:absolute;left:1ch;top:1.2em;width:4ch;height:1.2em;background-color:#000"></div>
+<div style="position:absolute;left:1ch;top:1.2em;width:4ch;height:1.2em;overflow:hidden">hi</div>
+</div>
+`, r.Builder.String())
+}
@@ -0,0 +1,108 @@
+package glint
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/go-glint/flex"
+)
+
+// LineRenderer renders output for non-interactive outputs such as CI logs
+// or piped output. It never emits cursor movement or other escape codes.
+//
+// Finalized components are written exactly once as plain lines. Components
+// that are not finalized are "live" and by default are not written at all
+// until they're finalized (which Document.Close does for all components).
+// If SnapshotInterval is set, the live components are additionally written
+// at most once per interval whenever their output changes.
+type LineRenderer struct {
+	// Output is where to write to.
+	Output io.Writer
+
+	// Width is a fixed width to set for the root node. If this isn't
+	// set then a width of 80 is arbitrarily used.
+	Width uint
+
+	// SnapshotInterval is the minimum duration between writing snapshots
+	// of live components. If this is zero, live components are never
+	// written until they're finalized. The interval is measured with the
+	// clock of the document (see Document.SetClock).
+	SnapshotInterval time.Duration
+
+	frame        frameInfo
+	lastSnapshot time.Time
+	lastLive     string
+}
+
+func (r *LineRenderer) beginFrame(f frameInfo) {
+	r.frame = f
+}
+
+func (r *LineRenderer) LayoutRoot() *flex.Node {
+	// If we don't have a writer set, then don't render anything.
+	if r.Output == nil {
+		return nil
+	}
+
+	width := r.Width
+	if width == 0 {
+		width = 80
+	}
+
+	node := flex.NewNode()
+	node.StyleSetWidth(float32(width))
+	return node
+}
+
+func (r *LineRenderer) RenderRoot(root, prev *flex.Node) {
+	var buf bytes.Buffer
+	var sr StringRenderer
+	sr.renderTree(&buf, root, nil)
+	lines := strings.Split(buf.String(), "\n")
+
+	// Determine the number of lines at the front of our output that are
+	// finalized. The document prunes these components after this frame so
+	// they will never be given to us again and we write them now.
+	frame := r.frame
+	r.frame = frameInfo{}
+	finalHeight := 0
+	if n := frame.finalized; n > 0 && n <= len(root.Children) {
+		finalHeight = nodeBottom(root.Children[n-1])
+	}
+	if finalHeight > len(lines) {
+		finalHeight = len(lines)
+	}
+
+	for _, line := range lines[:finalHeight] {
+		io.WriteString(r.Output, line+"\n")
+	}
+
+	// If we wrote finalized output then the live output we snapshotted
+	// is no longer the most recent thing written so we reset it.
+	if finalHeight > 0 {
+		r.lastLive = ""
+	}
+
+	// Write a snapshot of the live components if enabled.
+	if r.SnapshotInterval <= 0 || finalHeight >= len(lines) {
+		return
+	}
+
+	live := strings.Join(lines[finalHeight:], "\n")
+	clock := frame.clock
+	if clock == nil {
+		clock = systemClock{}
+	}
+
+	now := clock.Now()
+	if live == r.lastLive ||
+		(!r.lastSnapshot.IsZero() && now.Sub(r.lastSnapshot) < r.SnapshotInterval) {
+		return
+	}
+
+	io.WriteString(r.Output, live+"\n")
+	r.lastLive = live
+	r.lastSnapshot = now
+}
@@ -0,0 +1,114 @@
+package glint
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLineRenderer(t *testing.T) {
+	require := require.New(t)
+
+	var buf bytes.Buffer
+	d := New()
+	d.SetRenderer(&LineRenderer{Output: &buf})
+	d.Append(Finalize(Text("hello")))
+	d.Append(Text("live"))
+
+	// Only the finalized component is written
+	d.RenderFrame()
+	require.Equal("hello\n", buf.String())
+
+	// Rendering again writes nothing new
+	d.RenderFrame()
+	require.Equal("hello\n", buf.String())
+
+	// Closing finalizes the live component
+	require.NoError(d.Close())
+	require.Equal("hello\nlive\n", buf.String())
+}
+
+func TestLineRenderer_finalizedFragment(t *testing.T) {
+	require := require.New(t)
+
+	var buf bytes.Buffer
+	d := New()
+	d.SetRenderer(&LineRenderer{Output: &buf})
+	d.Append(Fragment(Finalize(Text("a")), Finalize(Text("b"))))
+	d.Append(Text("live"))
+
+	// The fragment isn't pruned by the document so it isn't written until
+	// it is finalized along with everything else.
+	d.RenderFrame()
+	d.RenderFrame()
+	require.Empty(buf.String())
+
+	require.NoError(d.Close())
+	require.Equal("a\nb\nlive\n", buf.String())
+}
+
+func TestLineRenderer_finalizedMargin(t *testing.T) {
+	require := require.New(t)
+
+	var buf bytes.Buffer
+	d := New()
+	d.SetRenderer(&LineRenderer{Output: &buf})
+	d.Append(Finalize(Layout(Text("hello")).MarginTop(1).MarginBottom(1)))
+	d.Append(Text("live"))
+
+	// The margins are written with the finalized component
+	d.RenderFrame()
+	require.Equal("\nhello\n\n", buf.String())
+
+	// Only the live component remains drawn
+	require.Equal(float32(1), d.prevRoot.LayoutGetHeight())
+	require.NoError(d.Close())
+	require.Equal("\nhello\n\nlive\n", buf.String())
+}
+
+func TestLineRenderer_snapshot(t *testing.T) {
+	require := require.New(t)
+
+	var value string
+	var buf bytes.Buffer
+	clock := NewTestClock(time.Time{})
+	d := New()
+	d.SetClock(clock)
+	d.SetRenderer(&LineRenderer{
+		Output:           &buf,
+		SnapshotInterval: time.Second,
+	})
+	d.Append(TextFunc(func(rows, cols uint) string { return value }))
+
+	// First frame writes a snapshot
+	value = "one"
+	d.RenderFrame()
+	require.Equal("one\n", buf.String())
+
+	// Changed output isn't written until the interval passes
+	value = "two"
+	clock.Advance(500 * time.Millisecond)
+	d.RenderFrame()
+	require.Equal("one\n", buf.String())
+	clock.Advance(500 * time.Millisecond)
+	d.RenderFrame()
+	require.Equal("one\ntwo\n", buf.String())
+
+	// Unchanged output isn't written again
+	clock.Advance(time.Second)
+	d.RenderFrame()
+	require.Equal("one\ntwo\n", buf.String())
+}
+
+func TestLineRenderer_noEscapes(t *testing.T) {
+	require := require.New(t)
+
+	var buf bytes.Buffer
+	d := New()
+	d.SetRenderer(&LineRenderer{Output: &buf})
+	d.Append(Style(Text("hello\nworld"), Color("red"), Bold()))
+	require.NoError(d.Close())
+	require.Equal("hello\nworld\n", buf.String())
+}
@@ -1,8 +1,6 @@
 package glint
 
 import (
-	"bytes"
-	"fmt"
 	"io"
 	"strings"
 
@@ -42,62 +40,22 @@ func (r *StringRenderer) RenderRoot(root, prev *flex.Node) {
 	r.Builder.Reset()
 
 	// Draw
-	r.renderTree(r.Builder, root, -1, false)
+	r.renderTree(r.Builder, root, nil)
 }
 
-func (r *StringRenderer) renderTree(final io.Writer, parent *flex.Node, lastRow int, color bool) {
-	var buf bytes.Buffer
-	for _, child := range parent.Children {
-		// Ignore children with a zero height
-		if child.LayoutGetHeight() == 0 {
-			continue
-		}
-
-		// If we're on a different row than last time then we draw a newline.
-		thisRow := int(child.LayoutGetTop())
-		if lastRow >= 0 && thisRow > lastRow {
-			buf.WriteByte('\n')
-		}
-		lastRow = thisRow
-
-		// Get our node context. If we don't have one then we're a container
-		// and we render below.
-		ctx, ok := child.Context.(*TextNodeContext)
-		if !ok {
-			r.renderTree(&buf, child, lastRow, color)
-		} else {
-			text := ctx.Text
-			if color {
-				text = styleRender(ctx.Context, text)
-			}
-
-			// Draw our text
-			fmt.Fprint(&buf, text)
-		}
-	}
-
-	// We've finished drawing our main content. If we have any paddings/margins
-	// we have to draw these now into our buffer.
-	leftMargin := int(parent.LayoutGetMargin(flex.EdgeLeft))
-	rightMargin := int(parent.LayoutGetMargin(flex.EdgeRight))
-	leftPadding := int(parent.LayoutGetPadding(flex.EdgeLeft))
-	rightPadding := int(parent.LayoutGetPadding(flex.EdgeRight))
+// renderTree renders the children of parent to final. If style is non-nil,
+// it is called to apply the style of each text node to its text.
+func (r *StringRenderer) renderTree(final io.Writer, parent *flex.Node, style textStyler) {
+	c := newCanvas(parent)
+	c.drawTree(parent, 0, 0)
+	c.write(final, style)
+}
 
-	// NOTE(mitchellh): this is not an optimal way to do this. This was a
-	// get-it-done-fast implementation. We should swing back around at some
-	// point and rewrite this with less allocations and copying.
-	lines := bytes.Split(buf.Bytes(), newline)
-	for i, line := range lines {
-		final.Write(bytes.Repeat(space, leftMargin+leftPadding))
-		final.Write(line)
-		final.Write(bytes.Repeat(space, rightMargin+rightPadding))
-		if i < len(lines)-1 {
-			final.Write(newline)
-		}
-	}
+// nodeBottom returns the row below the node in its parent, including
+// its bottom margin.
+func nodeBottom(n *flex.Node) int {
+	return int(n.LayoutGetTop() + n.LayoutGetHeight() + n.LayoutGetMargin(flex.EdgeBottom))
 }
 
-var (
-	space   = []byte(" ")
-	newline = []byte("\n")
-)
+// textStyler applies a style to text for a renderer.
+type textStyler func(TextStyle, string) string
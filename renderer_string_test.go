@@ -34,3 +34,53 @@ func TestStringRenderer_blankText(t *testing.T) {
 	d.RenderFrame()
 	require.Equal("\nhello", r.Builder.String())
 }
+
+func TestStringRenderer_canvas(t *testing.T) {
+	cases := []struct {
+		Name     string
+		C        Component
+		Expected string
+	}{
+		{
+			"side by side",
+			Layout(Text("a\nb\nc"), Text("d\ne")).Row(),
+			"ad\nbe\nc",
+		},
+		{
+			"columns",
+			Layout(
+				Layout(Text("one\ntwo")).Width(5),
+				Layout(Text("three\nfour")).Width(6),
+				Text("five"),
+			).Row(),
+			"one  three five\ntwo  four",
+		},
+		{
+			"panels with padding",
+			Layout(
+				Layout(Text("left\nside")).PaddingRight(2),
+				Layout(Text("right")).MarginTop(1),
+			).Row(),
+			"left  \nside  right",
+		},
+		{
+			"wrapped rows",
+			Layout(Text("a\nb"), Text("ccc"), Text("d\ne")).Row().Width(4).Wrap(),
+			"accc\nb\nd\ne",
+		},
+		{
+			"nested rows in column",
+			Layout(
+				Layout(Text("1\n2"), Text("3")).Row(),
+				Text("4"),
+			),
+			"13\n2\n4",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			require.Equal(t, tt.Expected, TestRender(t, tt.C))
+		})
+	}
+}
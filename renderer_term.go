@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sync"
 
 	"github.com/containerd/console"
 	"github.com/gookit/color"
@@ -24,6 +25,104 @@ type TerminalRenderer struct {
 	// (zero), then we will auto-detect the size of the output if it is a TTY.
 	// If the values are still zero, nothing will be rendered.
 	Rows, Cols uint
+
+	// Fullscreen, if true, draws to the alternate screen buffer and
+	// constrains the height of the root to Rows so that components can fill
+	// the screen. The primary screen is restored on Close. Since nothing
+	// drawn to the alternate screen remains after Close, finalized
+	// components are not preserved in this mode.
+	Fullscreen bool
+
+	altScreen bool
+
+	// mu protects the fields below, which are used to cache the size of
+	// the terminal and notify of changes to it.
+	mu         sync.Mutex
+	sizeCached bool
+	rows, cols uint
+	refreshCh  chan struct{}
+	watching   bool
+	stopWatch  func()
+
+	// sizeFile is the terminal that the size is read from when the
+	// terminal is resized.
+	sizeFile *os.File
+}
+
+// Resize notifies the renderer that the terminal was resized. This is
+// called automatically when the terminal sends a window change signal on
+// platforms that support it and Rows, Cols are not set. This can also be
+// called manually to inject resize events, such as for tests. This will
+// request an immediate frame from an active render loop.
+func (r *TerminalRenderer) Resize(rows, cols uint) {
+	r.mu.Lock()
+	r.rows, r.cols = rows, cols
+	r.sizeCached = true
+	if r.refreshCh == nil {
+		r.refreshCh = make(chan struct{}, 1)
+	}
+	ch := r.refreshCh
+	r.mu.Unlock()
+
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// Refresh implements RendererRefresher.
+func (r *TerminalRenderer) Refresh() <-chan struct{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.refreshCh == nil {
+		r.refreshCh = make(chan struct{}, 1)
+	}
+
+	return r.refreshCh
+}
+
+// size returns the size of the terminal. If we're able to watch for size
+// changes then the size is cached and only a resize will change it.
+// Otherwise, this requires a syscall on every call.
+func (r *TerminalRenderer) size() (rows, cols uint) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.sizeCached {
+		return r.rows, r.cols
+	}
+
+	f, ok := r.Output.(*os.File)
+	if !ok || !sshterm.IsTerminal(int(f.Fd())) {
+		return 0, 0
+	}
+
+	rows, cols = termSize(f)
+	if !r.watching {
+		r.sizeFile = f
+		r.watching = r.watchResize()
+	}
+	if r.watching {
+		r.rows, r.cols = rows, cols
+		r.sizeCached = true
+	}
+
+	return rows, cols
+}
+
+// termSize returns the size of the terminal f. This returns zeros if the
+// size can't be determined.
+func termSize(f *os.File) (rows, cols uint) {
+	c, err := console.ConsoleFromFile(f)
+	if err != nil {
+		return 0, 0
+	}
+
+	sz, err := c.Size()
+	if err != nil {
+		return 0, 0
+	}
+
+	return uint(sz.Height), uint(sz.Width)
 }
 
 func (r *TerminalRenderer) LayoutRoot() *flex.Node {
@@ -36,14 +135,7 @@ func (r *TerminalRenderer) LayoutRoot() *flex.Node {
 	cols := r.Cols
 	rows := r.Rows
 	if cols == 0 || rows == 0 {
-		if f, ok := r.Output.(*os.File); ok && sshterm.IsTerminal(int(f.Fd())) {
-			if c, err := console.ConsoleFromFile(f); err == nil {
-				if sz, err := c.Size(); err == nil {
-					rows = uint(sz.Height)
-					cols = uint(sz.Width)
-				}
-			}
-		}
+		rows, cols = r.size()
 	}
 
 	// Render nothing if we're going to have any zero dimensions
@@ -54,6 +146,9 @@ func (r *TerminalRenderer) LayoutRoot() *flex.Node {
 	// Setup our node
 	node := flex.NewNode()
 	node.StyleSetWidth(float32(cols))
+	if r.Fullscreen {
+		node.StyleSetHeight(float32(rows))
+	}
 	node.Context = &termRootContext{
 		Rows: rows,
 		Cols: cols,
@@ -70,8 +165,19 @@ func (r *TerminalRenderer) RenderRoot(root, prev *flex.Node) {
 	// a blank screen.
 	var buf bytes.Buffer
 	var sr StringRenderer
-	sr.renderTree(&buf, root, -1, color.IsSupportColor())
+	var style textStyler
+	if color.IsSupportColor() {
+		style = TextStyle.ansi
+	}
+	sr.renderTree(&buf, root, style)
 	rootCtx.Buf = &buf
+	rootCtx.Cells = parseCells(buf.Bytes())
+	rootCtx.Height = uint(root.LayoutGetHeight())
+
+	if r.Fullscreen {
+		r.renderFullscreen(rootCtx, prev)
+		return
+	}
 
 	if prev != nil {
 		// If the previous draw was a terminal and the output was identical,
@@ -94,6 +200,46 @@ func (r *TerminalRenderer) RenderRoot(root, prev *flex.Node) {
 			// was finalized and we need to start on a new line.
 			fmt.Fprintf(w, "\n")
 		} else {
+			// If the size is unchanged and everything we drew is still on
+			// the screen, then we only update the cells that changed.
+			if ok &&
+				prevCtx != nil &&
+				prevCtx.Cells != nil &&
+				prevCtx.Rows == rootCtx.Rows &&
+				prevCtx.Cols == rootCtx.Cols &&
+				height <= rootCtx.Rows &&
+				height <= prevCtx.Height {
+				// The previous height may be less than what was drawn if
+				// there were finalized components. Those are drawn at the
+				// top and will not change so we only diff what is below them.
+				finalized := int(prevCtx.Height - height)
+				if finalized < len(prevCtx.Cells) {
+					var out bytes.Buffer
+					renderCellsDiff(&out, prevCtx.Cells[finalized:], rootCtx.Cells)
+					io.Copy(w, &out)
+					return
+				}
+			}
+
+			// If the terminal got narrower, then lines we drew that are
+			// now wider than the terminal were reflowed onto multiple lines
+			// and we have to clear all of those.
+			if ok &&
+				prevCtx != nil &&
+				prevCtx.Cells != nil &&
+				prevCtx.Cols > rootCtx.Cols &&
+				height <= prevCtx.Height {
+				if finalized := int(prevCtx.Height - height); finalized < len(prevCtx.Cells) {
+					height = 0
+					for _, row := range prevCtx.Cells[finalized:] {
+						height += (uint(len(row)) + rootCtx.Cols - 1) / rootCtx.Cols
+						if len(row) == 0 {
+							height++
+						}
+					}
+				}
+			}
+
 			if height <= rootCtx.Rows {
 				// Delete current line
 				fmt.Fprint(w, b.Column(0).EraseLine(aec.EraseModes.All).ANSI)
@@ -113,7 +259,180 @@ func (r *TerminalRenderer) RenderRoot(root, prev *flex.Node) {
 	io.Copy(w, bytes.NewReader(buf.Bytes()))
 }
 
+// renderFullscreen draws the root to the alternate screen. The root always
+// fills the screen so unlike inline rendering, finalized components at the
+// top of the previous frame do not change what we diff against.
+func (r *TerminalRenderer) renderFullscreen(rootCtx *termRootContext, prev *flex.Node) {
+	// Anything beyond the bottom of the screen can't be drawn.
+	if uint(len(rootCtx.Cells)) > rootCtx.Rows {
+		rootCtx.Cells = rootCtx.Cells[:rootCtx.Rows]
+	}
+
+	// The root fills the screen, but we only draw up to the last row
+	// with content.
+	for len(rootCtx.Cells) > 1 && len(rootCtx.Cells[len(rootCtx.Cells)-1]) == 0 {
+		rootCtx.Cells = rootCtx.Cells[:len(rootCtx.Cells)-1]
+	}
+
+	var prevCtx *termRootContext
+	if prev != nil {
+		prevCtx, _ = prev.Context.(*termRootContext)
+	}
+
+	var out bytes.Buffer
+	if !r.altScreen {
+		out.WriteString(altScreenEnter + cursorHide)
+		r.altScreen = true
+		prevCtx = nil
+	}
+
+	if prevCtx != nil &&
+		prevCtx.Cells != nil &&
+		prevCtx.Rows == rootCtx.Rows &&
+		prevCtx.Cols == rootCtx.Cols {
+		if bytes.Equal(prevCtx.Buf.Bytes(), rootCtx.Buf.Bytes()) {
+			return
+		}
+
+		renderCellsDiff(&out, prevCtx.Cells, rootCtx.Cells)
+	} else {
+		out.WriteString(b.EraseDisplay(aec.EraseModes.All).ANSI.String())
+		for y, row := range rootCtx.Cells {
+			out.WriteString(b.Position(uint(y+1), 1).ANSI.String())
+			writeCells(&out, row)
+		}
+	}
+
+	io.Copy(r.Output, &out)
+}
+
+// renderCellsDiff writes the escape sequences and characters necessary to
+// turn the prev cells on the screen into the next cells. The cursor is
+// expected to be at the end of the last row of prev and is left at the end
+// of the last row of next, which is where a full draw would leave it.
+func renderCellsDiff(out *bytes.Buffer, prev, next [][]cell) {
+	// row is the row the cursor is on, relative to the first row of prev.
+	row := len(prev) - 1
+	moveRow := func(y int) {
+		if y < row {
+			out.WriteString(b.Up(uint(row - y)).ANSI.String())
+		} else if y > row {
+			out.WriteString(b.Down(uint(y - row)).ANSI.String())
+		}
+		row = y
+	}
+	moveTo := func(y, x int) {
+		moveRow(y)
+
+		// We always set the column absolutely since the cursor may be
+		// in a pending wrap state at the end of a line.
+		out.WriteString(b.Column(uint(x + 1)).ANSI.String())
+	}
+
+	// Update the rows that exist in both frames.
+	for y := 0; y < len(prev) && y < len(next); y++ {
+		p, n := prev[y], next[y]
+
+		// Cells are diffed by index, which is only the column if every
+		// character is one column wide. Otherwise, we redraw the row.
+		if hasWideCell(p) || hasWideCell(n) {
+			moveTo(y, 0)
+			writeCells(out, n)
+			if cellsWidth(p) > cellsWidth(n) {
+				out.WriteString(b.EraseLine(aec.EraseModes.Tail).ANSI.String())
+			}
+
+			continue
+		}
+
+		for x := 0; x < len(n); {
+			if x < len(p) && p[x] == n[x] {
+				x++
+				continue
+			}
+
+			// Find the run of changed cells and draw them all at once.
+			start := x
+			for x < len(n) && (x >= len(p) || p[x] != n[x]) {
+				x++
+			}
+
+			moveTo(y, start)
+			writeCells(out, n[start:x])
+		}
+
+		// If the row got shorter, erase the remainder.
+		if len(p) > len(n) {
+			moveTo(y, len(n))
+			out.WriteString(b.EraseLine(aec.EraseModes.Tail).ANSI.String())
+		}
+	}
+
+	// Erase any rows that no longer exist.
+	for y := len(next); y < len(prev); y++ {
+		moveTo(y, 0)
+		out.WriteString(b.EraseLine(aec.EraseModes.All).ANSI.String())
+	}
+
+	// Draw any new rows. We use newlines here since the rows below the
+	// previous frame may not exist yet and we may have to scroll.
+	if len(next) > len(prev) {
+		moveRow(len(prev) - 1)
+		for y := len(prev); y < len(next); y++ {
+			out.WriteString("\n")
+			row = y
+			moveTo(y, 0)
+			writeCells(out, next[y])
+		}
+	}
+
+	// Leave the cursor at the end of our last row.
+	last := len(next) - 1
+	moveTo(last, cellsWidth(next[last]))
+}
+
+func (r *TerminalRenderer) mouseOrigin() (x, y int, ok bool) {
+	return 0, 0, r.Fullscreen
+}
+
+func (r *TerminalRenderer) terminalOutput() io.Writer {
+	return r.Output
+}
+
+// outputChanged must be called after Output is changed to a different file
+// for the same terminal, such as by CaptureOutput, so that resizes are
+// read from the new file.
+func (r *TerminalRenderer) outputChanged() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if f, ok := r.Output.(*os.File); ok && r.sizeFile != nil {
+		r.sizeFile = f
+	}
+}
+
+// restoreTerminal leaves the alternate screen if we entered it. The next
+// frame enters it again.
+func (r *TerminalRenderer) restoreTerminal() {
+	if r.altScreen {
+		r.altScreen = false
+		fmt.Fprint(r.Output, cursorShow+altScreenExit)
+	}
+}
+
 func (r *TerminalRenderer) Close() error {
+	r.mu.Lock()
+	if r.stopWatch != nil {
+		r.stopWatch()
+		r.stopWatch = nil
+	}
+	r.mu.Unlock()
+
+	if r.altScreen {
+		r.altScreen = false
+		fmt.Fprint(r.Output, cursorShow+altScreenExit)
+		return nil
+	}
+
 	fmt.Fprintln(r.Output, "")
 	return nil
 }
@@ -121,6 +440,19 @@ func (r *TerminalRenderer) Close() error {
 type termRootContext struct {
 	Rows, Cols uint
 	Buf        *bytes.Buffer
+
+	// Cells is the output in Buf parsed into cells, and Height is the
+	// height of the root at the time it was drawn. These are used to only
+	// redraw the cells that change between frames.
+	Cells  [][]cell
+	Height uint
 }
 
 var b = aec.EmptyBuilder
+
+const (
+	altScreenEnter = "\x1b[?1049h"
+	altScreenExit  = "\x1b[?1049l"
+	cursorHide     = "\x1b[?25l"
+	cursorShow     = "\x1b[?25h"
+)
@@ -0,0 +1,311 @@
+package glint
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mitchellh/go-glint/termtest"
+)
+
+func TestTerminalRenderer_diff(t *testing.T) {
+	require := require.New(t)
+
+	var value string
+	var buf bytes.Buffer
+	d := New()
+	d.SetRenderer(&TerminalRenderer{Output: &buf, Rows: 10, Cols: 20})
+	d.Append(TextFunc(func(rows, cols uint) string { return value }))
+
+	// First frame draws everything
+	value = "hello\nworld"
+	d.RenderFrame()
+	require.Equal("hello\nworld", buf.String())
+
+	// Unchanged frames draw nothing
+	buf.Reset()
+	d.RenderFrame()
+	require.Empty(buf.String())
+
+	// A single changed cell only redraws that cell
+	buf.Reset()
+	value = "hallo\nworld"
+	d.RenderFrame()
+	require.Equal("\x1b[1A\x1b[2Ga\x1b[1B\x1b[6G", buf.String())
+
+	// Shorter rows are erased and new rows are appended
+	buf.Reset()
+	value = "hallo\nwor\n!"
+	d.RenderFrame()
+	require.Equal("\x1b[4G\x1b[0K\n\x1b[1G!\x1b[2G", buf.String())
+}
+
+func TestRenderCellsDiff_removedRows(t *testing.T) {
+	require := require.New(t)
+
+	var buf bytes.Buffer
+	renderCellsDiff(&buf,
+		parseCells([]byte("one\ntwo\nthree")),
+		parseCells([]byte("one")),
+	)
+	require.Equal("\x1b[1A\x1b[1G\x1b[2K\x1b[1B\x1b[1G\x1b[2K\x1b[2A\x1b[4G", buf.String())
+}
+
+func TestRenderCellsDiff_wide(t *testing.T) {
+	require := require.New(t)
+
+	// Rows with wide characters are redrawn since cells after them
+	// aren't in the column of their index.
+	var buf bytes.Buffer
+	renderCellsDiff(&buf,
+		parseCells([]byte("a中b\nxy")),
+		parseCells([]byte("a中c\nxz")),
+	)
+	require.Equal("\x1b[1A\x1b[1Ga中c\x1b[1B\x1b[2Gz\x1b[3G", buf.String())
+
+	// Shrinking a row with a wide character erases the remainder and
+	// the cursor is left after the last column.
+	buf.Reset()
+	renderCellsDiff(&buf,
+		parseCells([]byte("中中")),
+		parseCells([]byte("中")),
+	)
+	require.Equal("\x1b[1G中\x1b[0K\x1b[3G", buf.String())
+}
+
+func TestParseCells(t *testing.T) {
+	require := require.New(t)
+
+	cells := parseCells([]byte("a\x1b[31mb\x1b[1mc\x1b[0md\n▄"))
+	require.Equal([][]cell{
+		{
+			{R: 'a'},
+			{R: 'b', SGR: "\x1b[31m"},
+			{R: 'c', SGR: "\x1b[31m\x1b[1m"},
+			{R: 'd'},
+		},
+		{
+			{R: '▄'},
+		},
+	}, cells)
+}
+
+func TestTerminalRenderer_fullscreen(t *testing.T) {
+	require := require.New(t)
+
+	var value string
+	var buf bytes.Buffer
+	r := &TerminalRenderer{Output: &buf, Rows: 3, Cols: 10, Fullscreen: true}
+	d := New()
+	d.SetRenderer(r)
+	d.Append(TextFunc(func(rows, cols uint) string { return value }))
+
+	// The root fills the screen
+	root := r.LayoutRoot()
+	require.Equal(float32(3), root.StyleGetHeight().Value)
+
+	// First frame switches to the alternate screen
+	value = "hello"
+	d.RenderFrame()
+	require.Equal("\x1b[?1049h\x1b[?25l\x1b[2J\x1b[1;1Hhello", buf.String())
+
+	// Later frames only update changes
+	buf.Reset()
+	value = "help"
+	d.RenderFrame()
+	require.Equal("\x1b[4Gp\x1b[5G\x1b[0K\x1b[5G", buf.String())
+
+	// Closing restores the primary screen
+	buf.Reset()
+	require.NoError(d.Close())
+	require.True(strings.HasSuffix(buf.String(), "\x1b[?25h\x1b[?1049l"))
+}
+
+func TestTerminalRenderer_resize(t *testing.T) {
+	require := require.New(t)
+
+	var buf bytes.Buffer
+	r := &TerminalRenderer{Output: &buf}
+	d := New()
+	d.SetRenderer(r)
+	d.Append(Text("hello"))
+
+	// Without a size nothing is rendered
+	d.RenderFrame()
+	require.Empty(buf.String())
+
+	// Inject a size
+	r.Resize(5, 20)
+	<-r.Refresh()
+	d.RenderFrame()
+	require.Equal("hello", buf.String())
+
+	// Shrinking reflows our previous line onto two lines, which must
+	// both be cleared.
+	buf.Reset()
+	r.Resize(5, 3)
+	select {
+	case <-r.Refresh():
+	default:
+		t.Fatal("resize should request a refresh")
+	}
+	d.RenderFrame()
+	require.Equal("\x1b[0G\x1b[2K\x1b[1A\x1b[0G\x1b[2Khel", buf.String())
+}
+
+func TestTerminalRenderer_emulated(t *testing.T) {
+	t.Run("multiple frames", func(t *testing.T) {
+		require := require.New(t)
+
+		term, r := testTerminal(5, 20)
+		term.Write([]byte("$ run\n"))
+
+		var value string
+		d := New()
+		d.SetRenderer(r)
+		d.Append(TextFunc(func(rows, cols uint) string { return value }))
+
+		for _, v := range []string{"one", "one\ntwo\nthree", "four", "", "five\nsix"} {
+			value = v
+			d.RenderFrame()
+			require.Equal(strings.TrimRight("$ run\n"+v, "\n"), term.String())
+		}
+
+		require.NoError(d.Close())
+		require.Equal("$ run\nfive\nsix", term.String())
+		row, col := term.Cursor()
+		require.Equal(3, row)
+		require.Equal(0, col)
+	})
+
+	t.Run("finalized components", func(t *testing.T) {
+		require := require.New(t)
+
+		term, r := testTerminal(5, 20)
+
+		var value string
+		d := New()
+		d.SetRenderer(r)
+		d.Append(Finalize(Text("done")))
+		d.Append(TextFunc(func(rows, cols uint) string { return value }))
+
+		value = "working"
+		d.RenderFrame()
+		require.Equal("done\nworking", term.String())
+
+		value = "still\nworking"
+		d.RenderFrame()
+		require.Equal("done\nstill\nworking", term.String())
+
+		d.Printf("log")
+		value = "w"
+		d.RenderFrame()
+		require.Equal("done\nlog\nw", term.String())
+
+		require.NoError(d.Close())
+		require.Equal("done\nlog\nw", term.String())
+	})
+
+	t.Run("taller than the screen", func(t *testing.T) {
+		require := require.New(t)
+
+		term, r := testTerminal(3, 20)
+
+		var value string
+		d := New()
+		d.SetRenderer(r)
+		d.Append(TextFunc(func(rows, cols uint) string { return value }))
+
+		value = "1\n2\n3\n4"
+		d.RenderFrame()
+		require.Equal("2\n3\n4", term.String())
+
+		value = "a\nb"
+		d.RenderFrame()
+		require.Equal("a\nb", term.String())
+	})
+
+	t.Run("resize narrower", func(t *testing.T) {
+		require := require.New(t)
+
+		term, r := testTerminal(5, 20)
+		term.Write([]byte("$ run\n"))
+
+		d := New()
+		d.SetRenderer(r)
+		d.Append(Text("hello world"))
+		d.RenderFrame()
+		require.Equal("$ run\nhello world", term.String())
+
+		// The terminal reflows our line onto two lines
+		term.Resize(5, 6)
+		r.Resize(5, 6)
+		require.Equal("$ run\nhello\nworld", term.String())
+
+		// Rendering clears both lines before drawing at the new width
+		d.RenderFrame()
+		require.Equal("$ run\nhello\nworld", term.String())
+		require.Equal([]string{"$ run", "hello", "world", "", ""}, term.Lines())
+	})
+
+	t.Run("resize wider", func(t *testing.T) {
+		require := require.New(t)
+
+		term, r := testTerminal(5, 6)
+
+		d := New()
+		d.SetRenderer(r)
+		d.Append(Text("hello world"))
+		d.RenderFrame()
+		require.Equal("hello\nworld", term.String())
+
+		term.Resize(5, 20)
+		r.Resize(5, 20)
+		d.RenderFrame()
+		require.Equal("hello world", term.String())
+	})
+
+	t.Run("fullscreen", func(t *testing.T) {
+		require := require.New(t)
+
+		term, r := testTerminal(3, 10)
+		r.Fullscreen = true
+		term.Write([]byte("$ run"))
+
+		var value string
+		d := New()
+		d.SetRenderer(r)
+		d.Append(TextFunc(func(rows, cols uint) string { return value }))
+
+		value = "one\ntwo"
+		d.RenderFrame()
+		require.True(term.AltScreen())
+		require.Equal("one\ntwo", term.String())
+
+		value = "three"
+		d.RenderFrame()
+		require.Equal("three", term.String())
+
+		term.Resize(2, 4)
+		r.Resize(2, 4)
+		value = "a\nb\nc"
+		d.RenderFrame()
+		require.Equal("a\nb", term.String())
+
+		require.NoError(d.Close())
+		// The primary screen was reflowed to the new size
+		require.False(term.AltScreen())
+		require.Equal("$ ru\nn", term.String())
+	})
+}
+
+// testTerminal returns a virtual terminal and a TerminalRenderer that
+// renders to it.
+func testTerminal(rows, cols int) (*termtest.Terminal, *TerminalRenderer) {
+	term := termtest.New(rows, cols)
+	r := &TerminalRenderer{Output: term}
+	r.Resize(uint(rows), uint(cols))
+	return term, r
+}
@@ -0,0 +1,44 @@
+//go:build !windows
+// +build !windows
+
+package glint
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// watchResize starts listening for window change signals and calls Resize
+// with the new size of sizeFile whenever one is received. This must be
+// called with the lock held.
+func (r *TerminalRenderer) watchResize() bool {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGWINCH)
+
+	doneCh := make(chan struct{})
+	r.stopWatch = func() {
+		signal.Stop(ch)
+		close(doneCh)
+	}
+
+	go func() {
+		for {
+			select {
+			case <-doneCh:
+				return
+
+			case <-ch:
+				r.mu.Lock()
+				f := r.sizeFile
+				r.mu.Unlock()
+
+				if rows, cols := termSize(f); rows > 0 && cols > 0 {
+					r.Resize(rows, cols)
+				}
+			}
+		}
+	}()
+
+	return true
+}
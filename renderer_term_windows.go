@@ -0,0 +1,7 @@
+package glint
+
+// watchResize is not supported on Windows since there is no window change
+// signal. The size of the terminal will be queried on every frame instead.
+func (r *TerminalRenderer) watchResize() bool {
+	return false
+}
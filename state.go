@@ -0,0 +1,98 @@
+package glint
+
+import (
+	"context"
+	"sync"
+)
+
+// State is a value attached to the position of a component in the render
+// tree. State is created with UseState.
+//
+// State survives re-renders as long as the component stays at the same
+// position in the tree with the same type (see Key to give components a
+// stable identity when siblings change). The state is released when the
+// component is removed from the tree. All methods are safe to call
+// concurrently, such as from goroutines started in Mount.
+type State struct {
+	mu    sync.Mutex
+	value interface{}
+	doc   *Document
+}
+
+// UseState returns the state for the component whose Body is being called
+// with ctx. The first time this is called for a component at a position in
+// the tree, the state is created with the initial value. On subsequent
+// renders, the existing state is returned and initial is ignored.
+//
+// Like hooks in Ink and React, state is identified by the order of the
+// calls to UseState within a single Body call, so a Body must always call
+// UseState the same number of times in the same order.
+//
+// If ctx is not the context given to Body, a new state that is not attached
+// to anything is returned.
+func UseState(ctx context.Context, initial interface{}) *State {
+	hooks, ok := ctx.Value(hooksCtxKey).(*componentHooks)
+	if !ok || hooks == nil {
+		return &State{value: initial}
+	}
+
+	idx := hooks.idx
+	hooks.idx++
+	if idx < len(hooks.states) {
+		return hooks.states[idx]
+	}
+
+	doc, _ := ctx.Value(documentCtxKey).(*Document)
+	s := &State{value: initial, doc: doc}
+	hooks.states = append(hooks.states, s)
+	return s
+}
+
+// Get returns the current value.
+func (s *State) Get() interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.value
+}
+
+// Set sets the value and invalidates the document so that the change is
+// rendered on the next frame.
+func (s *State) Set(v interface{}) {
+	s.mu.Lock()
+	s.value = v
+	doc := s.doc
+	s.mu.Unlock()
+
+	if doc != nil {
+		doc.Invalidate()
+	}
+}
+
+// Update atomically sets the value to the result of calling f with the
+// current value and invalidates the document. This should be used instead
+// of Get and Set when the new value depends on the old value.
+func (s *State) Update(f func(interface{}) interface{}) {
+	s.mu.Lock()
+	s.value = f(s.value)
+	doc := s.doc
+	s.mu.Unlock()
+
+	if doc != nil {
+		doc.Invalidate()
+	}
+}
+
+// componentHooks is the state for a single node in the render tree.
+type componentHooks struct {
+	states []*State
+
+	// idx is the index of the next call to UseState in the current call
+	// to Body.
+	idx int
+}
+
+type hooksCtxKeyType struct{}
+
+var hooksCtxKey = hooksCtxKeyType{}
+
+const documentCtxKey = glintCtxKey("document")
@@ -0,0 +1,114 @@
+package glint
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUseState(t *testing.T) {
+	require := require.New(t)
+
+	var state *State
+	c := &testStateParent{f: func(s *State) { state = s }}
+	d := NewTestDriver(t, c)
+	require.Equal("0", d.Step(0))
+
+	// The child is constructed fresh each frame but keeps its state.
+	state.Set(1)
+	require.Equal("1", d.Step(0))
+	state.Update(func(v interface{}) interface{} { return v.(int) + 1 })
+	require.Equal("2", d.Step(0))
+
+	// Removing the component releases the state
+	d.Document.Set()
+	d.Step(0)
+	d.Document.Set(c)
+	require.Equal("0", d.Step(0))
+}
+
+func TestUseState_printf(t *testing.T) {
+	require := require.New(t)
+
+	var state *State
+	c := &testStateParent{f: func(s *State) { state = s }}
+	d := NewTestDriver(t, c)
+	require.Equal("0", d.Step(0))
+
+	// Printed text doesn't reset the state of the live components.
+	state.Set(5)
+	d.Document.Printf("log")
+	require.Equal("log\n5", d.Step(0))
+	require.Equal("5", d.Step(0))
+}
+
+func TestUseState_multiple(t *testing.T) {
+	require := require.New(t)
+
+	var a, b *State
+	c := &testStateFunc{f: func(ctx context.Context) Component {
+		a = UseState(ctx, "a")
+		b = UseState(ctx, "b")
+		return Text(a.Get().(string) + b.Get().(string))
+	}}
+
+	d := NewTestDriver(t, c)
+	require.Equal("ab", d.Step(0))
+	b.Set("c")
+	require.Equal("ac", d.Step(0))
+}
+
+func TestUseState_invalidates(t *testing.T) {
+	require := require.New(t)
+
+	var state *State
+	c := &testStateDirtier{testStateFunc{f: func(ctx context.Context) Component {
+		state = UseState(ctx, 0)
+		return Text(fmt.Sprintf("%d", state.Get()))
+	}}}
+
+	d := NewTestDriver(t, c)
+	require.Equal("0", d.Step(0))
+	require.Equal("0", d.Step(0))
+	require.Len(d.Frames, 1)
+
+	// Even though the component is never dirty, setting state renders.
+	state.Set(1)
+	require.Equal("1", d.Step(0))
+	require.Len(d.Frames, 2)
+}
+
+func TestUseState_noBody(t *testing.T) {
+	require := require.New(t)
+
+	s := UseState(context.Background(), 42)
+	require.Equal(42, s.Get())
+	s.Set(12)
+	require.Equal(12, s.Get())
+}
+
+type testStateParent struct {
+	f func(*State)
+}
+
+func (c *testStateParent) Body(context.Context) Component {
+	return &testStateFunc{f: func(ctx context.Context) Component {
+		state := UseState(ctx, 0)
+		c.f(state)
+		return Text(fmt.Sprintf("%d", state.Get()))
+	}}
+}
+
+type testStateFunc struct {
+	f func(context.Context) Component
+}
+
+func (c *testStateFunc) Body(ctx context.Context) Component { return c.f(ctx) }
+
+type testStateDirtier struct {
+	testStateFunc
+}
+
+func (c *testStateDirtier) Dirty(context.Context) bool { return false }
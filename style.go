@@ -2,39 +2,120 @@ package glint
 
 import (
 	"context"
+	"strconv"
+	"strings"
 
 	"github.com/gookit/color"
 )
 
 // Style applies visual styles to this component and any children. This
 // can be used to set a foreground color, for example, to a set of components.
+//
+// Styles are nested: if a style is applied within another style, the inner
+// style takes precedence for any attributes it sets.
 func Style(inner Component, opts ...StyleOption) Component {
-	c := &styleComponent{inner: inner}
-	for _, opt := range opts {
-		opt(c)
-	}
+	return &styleComponent{inner: inner, opts: opts}
+}
+
+// TextStyle is the resolved style for text. This is available to renderers
+// on TextNodeContext so that any renderer can map styles to its own
+// medium. The zero value is unstyled text.
+type TextStyle struct {
+	// Foreground and Background are the text colors.
+	Foreground, Background StyleColor
 
-	return c
+	// Bold, Italic, and Underline are text attributes.
+	Bold, Italic, Underline bool
 }
 
-// styleRender is used internally to apply styles to the given string. The
-// ctx should be the same context given when Body was called on this
-// component.
-func styleRender(ctx context.Context, v string) string {
+// StyleColor is a color in a TextStyle. A color is either one of the named
+// colors supported by Color and BGColor or an arbitrary RGB value.
+type StyleColor struct {
+	// Set is true if a color is set. If this is false, the default color
+	// of the medium should be used.
+	Set bool
+
+	// Name is the name of the color, such as "red", for named colors.
+	// Terminals render named colors using their own palette. This is
+	// empty for RGB colors.
+	Name string
+
+	// R, G, B is the RGB value of the color. For named colors, this is
+	// a typical value for the color.
+	R, G, B uint8
+}
+
+// styleFromContext returns the resolved style for the given context. The
+// ctx should be the same context given when Body was called on a component.
+func styleFromContext(ctx context.Context) TextStyle {
+	var result TextStyle
 	value, _ := ctx.Value(styleCtxKey).([]*styleComponent)
 	for _, s := range value {
-		v = s.render(v)
+		for _, opt := range s.opts {
+			opt(&result)
+		}
+	}
+
+	return result
+}
+
+// ansi returns v with the ANSI escape codes necessary to render this
+// style. If the output doesn't support color, v is returned as-is.
+func (s TextStyle) ansi(v string) string {
+	codes := s.ansiCodes()
+	if codes == "" {
+		return v
 	}
 
-	return v
+	return color.RenderCode(codes, v)
+}
+
+// ansiCodes returns the SGR parameters for this style, or an empty string
+// if the style has no attributes.
+func (s TextStyle) ansiCodes() string {
+	var codes []string
+	if s.Bold {
+		codes = append(codes, color.OpBold.Code())
+	}
+	if s.Italic {
+		codes = append(codes, color.OpItalic.Code())
+	}
+	if s.Underline {
+		codes = append(codes, color.OpUnderscore.Code())
+	}
+	if s.Foreground.Set {
+		codes = append(codes, s.Foreground.ansi(false))
+	}
+	if s.Background.Set {
+		codes = append(codes, s.Background.ansi(true))
+	}
+
+	return strings.Join(codes, ";")
+}
+
+// ansi returns the SGR parameters for this color.
+func (c StyleColor) ansi(bg bool) string {
+	if c.Name != "" {
+		if named, ok := namedColors[c.Name]; ok {
+			code := named.code
+			if bg {
+				code += 10
+			}
+
+			return strconv.Itoa(int(code))
+		}
+	}
+
+	return color.RGB(c.R, c.G, c.B, bg).String()
 }
 
 type styleComponent struct {
-	inner            Component
-	fgColor, bgColor colorizer
-	style            []color.Color
+	inner Component
+	opts  []StyleOption
 }
 
+func (c *styleComponent) Dirty(context.Context) bool { return false }
+
 func (c *styleComponent) Body(ctx context.Context) Component {
 	// Add our style to the list of styles. We have to use copy here
 	// so we don't append to a parent.
@@ -45,37 +126,24 @@ func (c *styleComponent) Body(ctx context.Context) Component {
 	return Context(c.inner, styleCtxKey, value)
 }
 
-func (c *styleComponent) render(v string) string {
-	if c.bgColor != nil {
-		v = c.bgColor.Sprint(v)
-	}
-	if c.fgColor != nil {
-		v = c.fgColor.Sprint(v)
-	}
-	v = color.Style(c.style).Sprint(v)
-
-	return v
-}
-
 type styleCtxKeyType struct{}
 
 var styleCtxKey = styleCtxKeyType{}
 
 // StyleOption is an option that can be set when creating Text components.
-type StyleOption func(t *styleComponent)
+type StyleOption func(s *TextStyle)
 
 // Color sets the color by name. The supported colors are listed below.
 //
 // black, red, green, yellow, blue, magenta, cyan, white, darkGray,
 // lightRed, lightGreen, lightYellow, lightBlue, lightMagenta, lightCyan,
 // lightWhite.
+//
+// The name "default" resets the color to the default color.
 func Color(name string) StyleOption {
-	return func(t *styleComponent) {
-		if c, ok := color.FgColors[name]; ok {
-			t.fgColor = c
-		}
-		if c, ok := color.ExFgColors[name]; ok {
-			t.fgColor = c
+	return func(s *TextStyle) {
+		if c, ok := namedColor(name); ok {
+			s.Foreground = c
 		}
 	}
 }
@@ -83,15 +151,17 @@ func Color(name string) StyleOption {
 // ColorHex sets the foreground color by hex code. The value can be
 // in formats AABBCC, #AABBCC, 0xAABBCC.
 func ColorHex(v string) StyleOption {
-	return func(t *styleComponent) {
-		t.fgColor = color.HEX(v)
+	return func(s *TextStyle) {
+		if c, ok := hexColor(v); ok {
+			s.Foreground = c
+		}
 	}
 }
 
 // ColorRGB sets the foreground color by RGB values.
 func ColorRGB(r, g, b uint8) StyleOption {
-	return func(t *styleComponent) {
-		t.fgColor = color.RGB(r, g, b)
+	return func(s *TextStyle) {
+		s.Foreground = StyleColor{Set: true, R: r, G: g, B: b}
 	}
 }
 
@@ -101,13 +171,12 @@ func ColorRGB(r, g, b uint8) StyleOption {
 // black, red, green, yellow, blue, magenta, cyan, white, darkGray,
 // lightRed, lightGreen, lightYellow, lightBlue, lightMagenta, lightCyan,
 // lightWhite.
+//
+// The name "default" resets the color to the default color.
 func BGColor(name string) StyleOption {
-	return func(t *styleComponent) {
-		if c, ok := color.BgColors[name]; ok {
-			t.bgColor = c
-		}
-		if c, ok := color.ExBgColors[name]; ok {
-			t.bgColor = c
+	return func(s *TextStyle) {
+		if c, ok := namedColor(name); ok {
+			s.Background = c
 		}
 	}
 }
@@ -115,39 +184,86 @@ func BGColor(name string) StyleOption {
 // BGColorHex sets the background color by hex code. The value can be
 // in formats AABBCC, #AABBCC, 0xAABBCC.
 func BGColorHex(v string) StyleOption {
-	return func(t *styleComponent) {
-		t.bgColor = color.HEX(v, true)
+	return func(s *TextStyle) {
+		if c, ok := hexColor(v); ok {
+			s.Background = c
+		}
 	}
 }
 
 // BGColorRGB sets the background color by RGB values.
 func BGColorRGB(r, g, b uint8) StyleOption {
-	return func(t *styleComponent) {
-		t.bgColor = color.RGB(r, g, b, true)
+	return func(s *TextStyle) {
+		s.Background = StyleColor{Set: true, R: r, G: g, B: b}
 	}
 }
 
 // Bold sets the text to bold.
 func Bold() StyleOption {
-	return func(t *styleComponent) {
-		t.style = append(t.style, color.OpBold)
+	return func(s *TextStyle) {
+		s.Bold = true
 	}
 }
 
 // Italic sets the text to italic.
 func Italic() StyleOption {
-	return func(t *styleComponent) {
-		t.style = append(t.style, color.OpItalic)
+	return func(s *TextStyle) {
+		s.Italic = true
 	}
 }
 
 // Underline sets the text to be underlined.
 func Underline() StyleOption {
-	return func(t *styleComponent) {
-		t.style = append(t.style, color.OpUnderscore)
+	return func(s *TextStyle) {
+		s.Underline = true
+	}
+}
+
+// namedColor returns the StyleColor for the given color name. The
+// "default" color returns an unset color.
+func namedColor(name string) (StyleColor, bool) {
+	if name == "default" {
+		return StyleColor{}, true
+	}
+
+	c, ok := namedColors[name]
+	if !ok {
+		return StyleColor{}, false
+	}
+
+	return StyleColor{Set: true, Name: name, R: c.r, G: c.g, B: c.b}, true
+}
+
+// hexColor parses a hex color in the formats AABBCC, #AABBCC, 0xAABBCC.
+func hexColor(v string) (StyleColor, bool) {
+	rgb := color.HexToRgb(v)
+	if len(rgb) != 3 {
+		return StyleColor{}, false
 	}
+
+	return StyleColor{Set: true, R: uint8(rgb[0]), G: uint8(rgb[1]), B: uint8(rgb[2])}, true
 }
 
-type colorizer interface {
-	Sprint(...interface{}) string
+// namedColors are the colors that can be set by name. The code is the
+// SGR foreground code and the RGB values are the typical VGA values.
+var namedColors = map[string]struct {
+	code    uint8
+	r, g, b uint8
+}{
+	"black":        {30, 0, 0, 0},
+	"red":          {31, 170, 0, 0},
+	"green":        {32, 0, 170, 0},
+	"yellow":       {33, 170, 85, 0},
+	"blue":         {34, 0, 0, 170},
+	"magenta":      {35, 170, 0, 170},
+	"cyan":         {36, 0, 170, 170},
+	"white":        {37, 170, 170, 170},
+	"darkGray":     {90, 85, 85, 85},
+	"lightRed":     {91, 255, 85, 85},
+	"lightGreen":   {92, 85, 255, 85},
+	"lightYellow":  {93, 255, 255, 85},
+	"lightBlue":    {94, 85, 85, 255},
+	"lightMagenta": {95, 255, 85, 255},
+	"lightCyan":    {96, 85, 255, 255},
+	"lightWhite":   {97, 255, 255, 255},
 }
@@ -0,0 +1,113 @@
+package glint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mitchellh/go-glint/flex"
+)
+
+func TestStyle(t *testing.T) {
+	cases := []struct {
+		Name     string
+		C        Component
+		Expected TextStyle
+	}{
+		{
+			"no style",
+			Text("hello"),
+			TextStyle{},
+		},
+
+		{
+			"named colors and attributes",
+			Style(Text("hello"), Color("red"), BGColor("lightBlue"), Bold(), Underline()),
+			TextStyle{
+				Foreground: StyleColor{Set: true, Name: "red", R: 170},
+				Background: StyleColor{Set: true, Name: "lightBlue", R: 85, G: 85, B: 255},
+				Bold:       true,
+				Underline:  true,
+			},
+		},
+
+		{
+			"rgb and hex",
+			Style(Text("hello"), ColorRGB(1, 2, 3), BGColorHex("#AABBCC")),
+			TextStyle{
+				Foreground: StyleColor{Set: true, R: 1, G: 2, B: 3},
+				Background: StyleColor{Set: true, R: 0xAA, G: 0xBB, B: 0xCC},
+			},
+		},
+
+		{
+			"unknown color",
+			Style(Text("hello"), Color("nope"), BGColorHex("nope")),
+			TextStyle{},
+		},
+
+		{
+			"nested inner takes precedence",
+			Style(Style(Text("hello"), Color("green")), Color("red"), Italic()),
+			TextStyle{
+				Foreground: StyleColor{Set: true, Name: "green", G: 170},
+				Italic:     true,
+			},
+		},
+
+		{
+			"nested default resets",
+			Style(Style(Text("hello"), Color("default")), Color("red")),
+			TextStyle{},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			require := require.New(t)
+
+			r := &testStyleRenderer{}
+			d := New()
+			d.SetRenderer(r)
+			d.Append(tt.C)
+			d.RenderFrame()
+			require.Len(r.styles, 1)
+			require.Equal(tt.Expected, r.styles[0])
+		})
+	}
+}
+
+func TestTextStyle_ansi(t *testing.T) {
+	require.Equal(t, "hello", TextStyle{}.ansi("hello"))
+	require.Equal(t, "", TextStyle{}.ansiCodes())
+	require.Equal(t, "1;31;44", TextStyle{
+		Foreground: StyleColor{Set: true, Name: "red"},
+		Background: StyleColor{Set: true, Name: "blue"},
+		Bold:       true,
+	}.ansiCodes())
+	require.Equal(t, "38;2;1;2;3", TextStyle{
+		Foreground: StyleColor{Set: true, R: 1, G: 2, B: 3},
+	}.ansiCodes())
+}
+
+// testStyleRenderer records the styles of all text nodes rendered.
+type testStyleRenderer struct {
+	StringRenderer
+
+	styles []TextStyle
+}
+
+func (r *testStyleRenderer) RenderRoot(root, prev *flex.Node) {
+	var walk func(*flex.Node)
+	walk = func(n *flex.Node) {
+		if ctx, ok := n.Context.(*TextNodeContext); ok {
+			r.styles = append(r.styles, ctx.Style)
+		}
+
+		for _, child := range n.Children {
+			walk(child)
+		}
+	}
+
+	walk(root)
+}
@@ -0,0 +1,628 @@
+// Package termtest provides a small virtual terminal emulator for testing
+// output that uses ANSI escape sequences, such as the output of
+// glint.TerminalRenderer.
+//
+// The emulator supports the subset of VT100 and xterm sequences that are
+// commonly used by line-oriented programs: cursor movement, erasing, SGR
+// styles, the alternate screen, and scrolling into a scrollback buffer.
+// Unsupported sequences are ignored.
+package termtest
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Terminal is a virtual terminal. Write output to it with Write and then
+// inspect the resulting screen and scrollback.
+//
+// Like a TTY with the default settings, a newline moves the cursor to the
+// start of the next line.
+type Terminal struct {
+	rows, cols int
+
+	// screen is the active screen. This is either primary or alt.
+	screen  []line
+	primary []line
+	alt     []line
+
+	scrollback []line
+
+	row, col    int
+	pendingWrap bool
+	savedRow    int
+	savedCol    int
+	style       string
+	hidden      bool
+	modes       map[int]bool
+
+	// pending is any incomplete escape sequence or character from the
+	// last call to Write.
+	pending []byte
+}
+
+// Cell is a single character cell on the screen.
+type Cell struct {
+	// R is the character in the cell. This is a space for blank cells.
+	R rune
+
+	// Style is the SGR parameters active when this cell was written, such
+	// as "1;31". Multiple SGR sequences are joined with ";". This is empty
+	// if the cell is unstyled.
+	Style string
+}
+
+// line is a single row of cells.
+type line struct {
+	cells []Cell
+
+	// wrapped is true if this line was wrapped onto the next line because
+	// the cursor reached the end of the line.
+	wrapped bool
+}
+
+// New creates a new terminal with the given size.
+func New(rows, cols int) *Terminal {
+	t := &Terminal{
+		rows:  rows,
+		cols:  cols,
+		modes: map[int]bool{},
+	}
+	t.primary = t.blankLines(rows)
+	t.screen = t.primary
+	return t
+}
+
+// Size returns the size of the terminal.
+func (t *Terminal) Size() (rows, cols int) {
+	return t.rows, t.cols
+}
+
+// Cursor returns the zero-indexed position of the cursor on the screen.
+func (t *Terminal) Cursor() (row, col int) {
+	return t.row, t.col
+}
+
+// CursorVisible returns true if the cursor is visible.
+func (t *Terminal) CursorVisible() bool {
+	return !t.hidden
+}
+
+// AltScreen returns true if the alternate screen is active.
+func (t *Terminal) AltScreen() bool {
+	return t.alt != nil
+}
+
+// Mode returns true if the given private mode (such as 2004 for bracketed
+// paste) is enabled with "CSI ? n h".
+func (t *Terminal) Mode(n int) bool {
+	return t.modes[n]
+}
+
+// Cell returns the cell at the given zero-indexed position on the screen.
+func (t *Terminal) Cell(row, col int) Cell {
+	return t.screen[row].cells[col]
+}
+
+// Lines returns the lines on the screen with trailing spaces removed.
+func (t *Terminal) Lines() []string {
+	return linesString(t.screen)
+}
+
+// Scrollback returns the lines that have scrolled off the top of the
+// primary screen, oldest first, with trailing spaces removed.
+func (t *Terminal) Scrollback() []string {
+	return linesString(t.scrollback)
+}
+
+// String returns the lines on the screen joined with newlines. Trailing
+// blank lines are removed.
+func (t *Terminal) String() string {
+	return strings.TrimRight(strings.Join(t.Lines(), "\n"), "\n")
+}
+
+// Write implements io.Writer and processes the output.
+func (t *Terminal) Write(p []byte) (int, error) {
+	b := append(t.pending, p...)
+	t.pending = nil
+	for len(b) > 0 {
+		n := t.process(b)
+		if n == 0 {
+			// Incomplete sequence, wait for more data.
+			t.pending = append([]byte(nil), b...)
+			break
+		}
+
+		b = b[n:]
+	}
+
+	return len(p), nil
+}
+
+// process processes the start of b and returns the number of bytes
+// consumed. This returns zero if b starts with an incomplete sequence.
+func (t *Terminal) process(b []byte) int {
+	switch b[0] {
+	case '\x1b':
+		return t.processEscape(b)
+
+	case '\n', '\v', '\f':
+		t.col = 0
+		t.linefeed()
+		return 1
+
+	case '\r':
+		t.col = 0
+		t.pendingWrap = false
+		return 1
+
+	case '\b':
+		if t.col > 0 {
+			t.col--
+		}
+		t.pendingWrap = false
+		return 1
+
+	case '\t':
+		t.col = (t.col/8 + 1) * 8
+		if t.col >= t.cols {
+			t.col = t.cols - 1
+		}
+		return 1
+	}
+
+	// Ignore any other control characters.
+	if b[0] < 0x20 || b[0] == 0x7f {
+		return 1
+	}
+
+	if !utf8.FullRune(b) {
+		return 0
+	}
+
+	r, size := utf8.DecodeRune(b)
+	t.put(r)
+	return size
+}
+
+func (t *Terminal) processEscape(b []byte) int {
+	if len(b) < 2 {
+		return 0
+	}
+
+	switch b[1] {
+	case '[':
+		// CSI sequences end with a byte in the range 0x40 to 0x7E.
+		for i := 2; i < len(b); i++ {
+			if b[i] >= 0x40 && b[i] <= 0x7e {
+				t.csi(string(b[2:i]), b[i])
+				return i + 1
+			}
+		}
+
+		return 0
+
+	case ']':
+		// OSC sequences end with BEL or ST. We ignore them.
+		for i := 2; i < len(b); i++ {
+			if b[i] == '\a' {
+				return i + 1
+			}
+			if b[i] == '\x1b' && i+1 < len(b) && b[i+1] == '\\' {
+				return i + 2
+			}
+		}
+
+		return 0
+
+	case '7':
+		t.savedRow, t.savedCol = t.row, t.col
+
+	case '8':
+		t.row, t.col = t.savedRow, t.savedCol
+		t.pendingWrap = false
+
+	case 'M':
+		// Reverse index
+		if t.row > 0 {
+			t.row--
+		} else {
+			copy(t.screen[1:], t.screen[:len(t.screen)-1])
+			t.screen[0] = t.blankLine()
+		}
+	}
+
+	return 2
+}
+
+func (t *Terminal) csi(params string, final byte) {
+	// Private modes
+	if strings.HasPrefix(params, "?") {
+		if final != 'h' && final != 'l' {
+			return
+		}
+
+		for _, n := range parseParams(params[1:], 0) {
+			t.setMode(n, final == 'h')
+		}
+
+		return
+	}
+
+	args := parseParams(params, 1)
+	arg := func(i, def int) int {
+		if i < len(args) && args[i] > 0 {
+			return args[i]
+		}
+
+		return def
+	}
+
+	// Any cursor movement clears the pending wrap state.
+	if final != 'm' {
+		t.pendingWrap = false
+	}
+
+	switch final {
+	case 'A':
+		t.row = clamp(t.row-arg(0, 1), 0, t.rows-1)
+
+	case 'B':
+		t.row = clamp(t.row+arg(0, 1), 0, t.rows-1)
+
+	case 'C':
+		t.col = clamp(t.col+arg(0, 1), 0, t.cols-1)
+
+	case 'D':
+		t.col = clamp(t.col-arg(0, 1), 0, t.cols-1)
+
+	case 'E':
+		t.row = clamp(t.row+arg(0, 1), 0, t.rows-1)
+		t.col = 0
+
+	case 'F':
+		t.row = clamp(t.row-arg(0, 1), 0, t.rows-1)
+		t.col = 0
+
+	case 'G':
+		t.col = clamp(arg(0, 1)-1, 0, t.cols-1)
+
+	case 'H', 'f':
+		t.row = clamp(arg(0, 1)-1, 0, t.rows-1)
+		t.col = clamp(arg(1, 1)-1, 0, t.cols-1)
+
+	case 'J':
+		switch mode := argZero(params); mode {
+		case 0:
+			t.eraseLine(t.row, t.col, t.cols)
+			for r := t.row + 1; r < t.rows; r++ {
+				t.screen[r] = t.blankLine()
+			}
+
+		case 1:
+			for r := 0; r < t.row; r++ {
+				t.screen[r] = t.blankLine()
+			}
+			t.eraseLine(t.row, 0, t.col+1)
+
+		case 2:
+			for r := range t.screen {
+				t.screen[r] = t.blankLine()
+			}
+
+		case 3:
+			t.scrollback = nil
+		}
+
+	case 'K':
+		switch mode := argZero(params); mode {
+		case 0:
+			t.eraseLine(t.row, t.col, t.cols)
+
+		case 1:
+			t.eraseLine(t.row, 0, t.col+1)
+
+		case 2:
+			t.eraseLine(t.row, 0, t.cols)
+		}
+
+	case 'm':
+		if params == "" || params == "0" {
+			t.style = ""
+		} else if t.style == "" {
+			t.style = params
+		} else {
+			t.style += ";" + params
+		}
+	}
+}
+
+func (t *Terminal) setMode(n int, enabled bool) {
+	t.modes[n] = enabled
+	switch n {
+	case 25:
+		t.hidden = !enabled
+
+	case 1049:
+		if enabled && t.alt == nil {
+			t.savedRow, t.savedCol = t.row, t.col
+			t.alt = t.blankLines(t.rows)
+			t.screen = t.alt
+			t.row, t.col = 0, 0
+		} else if !enabled && t.alt != nil {
+			t.alt = nil
+			t.screen = t.primary
+			t.row, t.col = t.savedRow, t.savedCol
+		}
+		t.pendingWrap = false
+	}
+}
+
+// put writes a character at the cursor and advances the cursor.
+func (t *Terminal) put(r rune) {
+	if t.pendingWrap {
+		t.screen[t.row].wrapped = true
+		t.col = 0
+		t.linefeed()
+	}
+
+	t.screen[t.row].cells[t.col] = Cell{R: r, Style: t.style}
+	if t.col == t.cols-1 {
+		t.pendingWrap = true
+	} else {
+		t.col++
+	}
+}
+
+// linefeed moves the cursor down one line, scrolling if the cursor is
+// on the last line.
+func (t *Terminal) linefeed() {
+	t.pendingWrap = false
+	if t.row < t.rows-1 {
+		t.row++
+		return
+	}
+
+	// Only the primary screen has scrollback.
+	if t.alt == nil {
+		t.scrollback = append(t.scrollback, t.screen[0])
+	}
+
+	copy(t.screen, t.screen[1:])
+	t.screen[len(t.screen)-1] = t.blankLine()
+}
+
+// eraseLine erases the cells in [start, end) of the given row.
+func (t *Terminal) eraseLine(row, start, end int) {
+	l := &t.screen[row]
+	for i := start; i < end && i < len(l.cells); i++ {
+		l.cells[i] = blankCell
+	}
+	if end >= t.cols {
+		l.wrapped = false
+	}
+}
+
+// Resize changes the size of the terminal. Like most modern terminals,
+// the primary screen and scrollback are reflowed to the new width: lines
+// that were wrapped are rejoined and long lines are wrapped again. The
+// alternate screen is truncated or extended.
+func (t *Terminal) Resize(rows, cols int) {
+	if t.alt != nil {
+		t.alt = t.resizeLines(t.alt, rows, cols)
+		t.screen = t.alt
+		t.row = clamp(t.row, 0, rows-1)
+		t.col = clamp(t.col, 0, cols-1)
+		t.savedRow, t.savedCol = t.reflow(rows, cols, t.savedRow, t.savedCol)
+	} else {
+		t.row, t.col = t.reflow(rows, cols, t.row, t.col)
+		t.screen = t.primary
+	}
+
+	t.rows, t.cols = rows, cols
+	t.pendingWrap = false
+}
+
+// reflow reflows the primary screen and scrollback to the given size and
+// returns the new position of the given cursor.
+func (t *Terminal) reflow(rows, cols, cursorRow, cursorCol int) (int, int) {
+	// Everything below the cursor that is blank is discarded.
+	all := append(t.scrollback, t.primary...)
+	cursorAbs := len(t.scrollback) + cursorRow
+	end := len(all)
+	for end > cursorAbs+1 && isBlank(all[end-1]) {
+		end--
+	}
+	all = all[:end]
+
+	// Rejoin the wrapped lines into logical lines, noting the logical
+	// line and offset of our cursor.
+	var logical [][]Cell
+	var cursorLine, cursorOffset int
+	var current []Cell
+	for i, l := range all {
+		if i == cursorAbs {
+			cursorLine = len(logical)
+			cursorOffset = len(current) + cursorCol
+		}
+
+		current = append(current, l.cells...)
+		if !l.wrapped {
+			logical = append(logical, current)
+			current = nil
+		}
+	}
+	if current != nil {
+		logical = append(logical, current)
+	}
+
+	// Rewrap each logical line to the new width.
+	var result []line
+	newCursorAbs, newCursorCol := 0, 0
+	for i, cells := range logical {
+		// Trim trailing blanks, but never before the cursor.
+		n := len(cells)
+		for n > 0 && cells[n-1] == blankCell {
+			n--
+		}
+		if i == cursorLine && n < cursorOffset {
+			n = cursorOffset
+		}
+		cells = cells[:n]
+
+		if i == cursorLine {
+			chunk := cursorOffset / cols
+			newCursorCol = cursorOffset % cols
+			if chunk > 0 && chunk*cols == n {
+				chunk--
+				newCursorCol = cols - 1
+			}
+			newCursorAbs = len(result) + chunk
+		}
+
+		for {
+			l := line{cells: make([]Cell, cols)}
+			for j := range l.cells {
+				l.cells[j] = blankCell
+			}
+
+			chunk := cells
+			if len(chunk) > cols {
+				chunk = chunk[:cols]
+			}
+			copy(l.cells, chunk)
+			cells = cells[len(chunk):]
+			l.wrapped = len(cells) > 0
+			result = append(result, l)
+			if len(cells) == 0 {
+				break
+			}
+		}
+	}
+
+	// The screen is the last rows lines, as long as the cursor is visible.
+	top := len(result) - rows
+	if top < 0 {
+		top = 0
+	}
+	if newCursorAbs < top {
+		top = newCursorAbs
+	}
+
+	t.scrollback = result[:top]
+	screen := result[top:]
+	if len(screen) > rows {
+		screen = screen[:rows]
+	}
+	for len(screen) < rows {
+		screen = append(screen, line{cells: blankCells(cols)})
+	}
+	t.primary = screen
+
+	return newCursorAbs - top, newCursorCol
+}
+
+// resizeLines truncates or extends the lines to the given size.
+func (t *Terminal) resizeLines(lines []line, rows, cols int) []line {
+	result := make([]line, rows)
+	for i := range result {
+		result[i] = line{cells: blankCells(cols)}
+		if i < len(lines) {
+			copy(result[i].cells, lines[i].cells)
+		}
+	}
+
+	return result
+}
+
+func (t *Terminal) blankLines(n int) []line {
+	result := make([]line, n)
+	for i := range result {
+		result[i] = t.blankLine()
+	}
+
+	return result
+}
+
+func (t *Terminal) blankLine() line {
+	return line{cells: blankCells(t.cols)}
+}
+
+func blankCells(n int) []Cell {
+	result := make([]Cell, n)
+	for i := range result {
+		result[i] = blankCell
+	}
+
+	return result
+}
+
+func isBlank(l line) bool {
+	for _, c := range l.cells {
+		if c != blankCell {
+			return false
+		}
+	}
+
+	return true
+}
+
+func linesString(lines []line) []string {
+	result := make([]string, len(lines))
+	for i, l := range lines {
+		var b strings.Builder
+		for _, c := range l.cells {
+			b.WriteRune(c.R)
+		}
+
+		result[i] = strings.TrimRight(b.String(), " ")
+	}
+
+	return result
+}
+
+// parseParams parses semicolon-separated numeric parameters. Empty or
+// invalid parameters are set to def.
+func parseParams(params string, def int) []int {
+	if params == "" {
+		return nil
+	}
+
+	parts := strings.Split(params, ";")
+	result := make([]int, len(parts))
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			n = def
+		}
+
+		result[i] = n
+	}
+
+	return result
+}
+
+// argZero returns the first parameter, defaulting to zero. This is used
+// for sequences such as erase where zero is a meaningful value.
+func argZero(params string) int {
+	args := parseParams(params, 0)
+	if len(args) == 0 {
+		return 0
+	}
+
+	return args[0]
+}
+
+func clamp(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+
+	return v
+}
+
+var blankCell = Cell{R: ' '}
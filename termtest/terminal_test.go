@@ -0,0 +1,238 @@
+package termtest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTerminal(t *testing.T) {
+	cases := []struct {
+		Name       string
+		Rows, Cols int
+		Input      string
+		Lines      []string
+		Scrollback []string
+		Row, Col   int
+	}{
+		{
+			"text",
+			3, 10,
+			"hello\nworld",
+			[]string{"hello", "world", ""},
+			nil,
+			1, 5,
+		},
+
+		{
+			"carriage return",
+			3, 10,
+			"hello\rj",
+			[]string{"jello", "", ""},
+			nil,
+			0, 1,
+		},
+
+		{
+			"wrap",
+			3, 4,
+			"abcdefg",
+			[]string{"abcd", "efg", ""},
+			nil,
+			1, 3,
+		},
+
+		{
+			"pending wrap at end of line",
+			3, 4,
+			"abcd",
+			[]string{"abcd", "", ""},
+			nil,
+			0, 3,
+		},
+
+		{
+			"scroll",
+			2, 10,
+			"one\ntwo\nthree",
+			[]string{"two", "three"},
+			[]string{"one"},
+			1, 5,
+		},
+
+		{
+			"cursor movement",
+			3, 10,
+			"hello\nworld\x1b[1A\x1b[2GX\x1b[1B\x1b[1GY",
+			[]string{"hXllo", "Yorld", ""},
+			nil,
+			1, 1,
+		},
+
+		{
+			"cursor position",
+			3, 10,
+			"\x1b[2;3Hhi",
+			[]string{"", "  hi", ""},
+			nil,
+			1, 4,
+		},
+
+		{
+			"erase line",
+			3, 10,
+			"hello\x1b[3G\x1b[0K\nworld\x1b[2K",
+			[]string{"he", "", ""},
+			nil,
+			1, 5,
+		},
+
+		{
+			"erase display",
+			3, 10,
+			"one\ntwo\nthree\x1b[2A\x1b[2G\x1b[0J",
+			[]string{"o", "", ""},
+			nil,
+			0, 1,
+		},
+
+		{
+			"erase scrollback",
+			2, 10,
+			"one\ntwo\nthree\x1b[2J\x1b[3J",
+			[]string{"", ""},
+			nil,
+			1, 5,
+		},
+
+		{
+			"unicode",
+			2, 10,
+			"▄▂",
+			[]string{"▄▂", ""},
+			nil,
+			0, 2,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			require := require.New(t)
+
+			term := New(tt.Rows, tt.Cols)
+			term.Write([]byte(tt.Input))
+			require.Equal(tt.Lines, term.Lines())
+			require.Equal(len(tt.Scrollback), len(term.Scrollback()))
+			if len(tt.Scrollback) > 0 {
+				require.Equal(tt.Scrollback, term.Scrollback())
+			}
+
+			row, col := term.Cursor()
+			require.Equal(tt.Row, row, "row")
+			require.Equal(tt.Col, col, "col")
+		})
+	}
+}
+
+func TestTerminal_partialWrites(t *testing.T) {
+	require := require.New(t)
+
+	term := New(2, 10)
+	for _, b := range []byte("a\x1b[31mb\x1b[0m▄") {
+		term.Write([]byte{b})
+	}
+
+	require.Equal("ab▄", term.String())
+	require.Equal(Cell{R: 'b', Style: "31"}, term.Cell(0, 1))
+	require.Equal(Cell{R: '▄'}, term.Cell(0, 2))
+}
+
+func TestTerminal_style(t *testing.T) {
+	require := require.New(t)
+
+	term := New(2, 10)
+	term.Write([]byte("\x1b[1m\x1b[32ma\x1b[0mb"))
+	require.Equal(Cell{R: 'a', Style: "1;32"}, term.Cell(0, 0))
+	require.Equal(Cell{R: 'b'}, term.Cell(0, 1))
+}
+
+func TestTerminal_altScreen(t *testing.T) {
+	require := require.New(t)
+
+	term := New(2, 10)
+	term.Write([]byte("hello"))
+	term.Write([]byte("\x1b[?1049h\x1b[?25lfull"))
+	require.True(term.AltScreen())
+	require.False(term.CursorVisible())
+	require.Equal("full", term.String())
+
+	term.Write([]byte("\x1b[?25h\x1b[?1049l"))
+	require.False(term.AltScreen())
+	require.True(term.CursorVisible())
+	require.Equal("hello", term.String())
+
+	row, col := term.Cursor()
+	require.Equal(0, row)
+	require.Equal(5, col)
+}
+
+func TestTerminal_modes(t *testing.T) {
+	require := require.New(t)
+
+	term := New(2, 10)
+	term.Write([]byte("\x1b[?2004h\x1b[?1000;1006h"))
+	require.True(term.Mode(2004))
+	require.True(term.Mode(1000))
+	require.True(term.Mode(1006))
+
+	term.Write([]byte("\x1b[?1000l"))
+	require.False(term.Mode(1000))
+}
+
+func TestTerminal_resize(t *testing.T) {
+	t.Run("narrower", func(t *testing.T) {
+		require := require.New(t)
+
+		term := New(3, 10)
+		term.Write([]byte("one\nhello"))
+		term.Resize(3, 3)
+		require.Equal([]string{"one", "hel", "lo"}, term.Lines())
+
+		row, col := term.Cursor()
+		require.Equal(2, row)
+		require.Equal(2, col)
+	})
+
+	t.Run("narrower scrolls", func(t *testing.T) {
+		require := require.New(t)
+
+		term := New(2, 10)
+		term.Write([]byte("one\nhello"))
+		term.Resize(2, 3)
+		require.Equal([]string{"hel", "lo"}, term.Lines())
+		require.Equal([]string{"one"}, term.Scrollback())
+	})
+
+	t.Run("wider rejoins", func(t *testing.T) {
+		require := require.New(t)
+
+		term := New(3, 3)
+		term.Write([]byte("hello\nab"))
+		term.Resize(3, 10)
+		require.Equal([]string{"hello", "ab", ""}, term.Lines())
+
+		row, col := term.Cursor()
+		require.Equal(1, row)
+		require.Equal(2, col)
+	})
+
+	t.Run("shorter", func(t *testing.T) {
+		require := require.New(t)
+
+		term := New(3, 10)
+		term.Write([]byte("one\ntwo\nthree"))
+		term.Resize(2, 10)
+		require.Equal([]string{"two", "three"}, term.Lines())
+		require.Equal([]string{"one"}, term.Scrollback())
+	})
+}
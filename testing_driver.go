@@ -0,0 +1,226 @@
+package glint
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mitchellh/go-testing-interface"
+
+	"github.com/mitchellh/go-glint/flex"
+)
+
+// TestDriver renders a Document across multiple frames for testing. Each
+// step advances a fake clock and renders a frame using a StringRenderer.
+// Every rendered frame and every mount and unmount event is recorded so
+// that tests can assert on animations, state changes, and finalization.
+//
+// The driver is not safe for concurrent use, but the components in the
+// document can be modified concurrently as usual.
+type TestDriver struct {
+	// Document is the document being rendered. Components can be added
+	// or replaced directly on the document between steps.
+	Document *Document
+
+	// Renderer is the renderer used. Its Width can be set to change the
+	// width of subsequent frames.
+	Renderer *StringRenderer
+
+	// Clock is the fake clock. This is only advanced by Step.
+	Clock *TestClock
+
+	// Frames are all the frames rendered so far, in order.
+	Frames []TestFrame
+
+	// Events are all the mount and unmount events so far, in order.
+	Events []TestEvent
+
+	t testing.T
+}
+
+// TestFrame is a single frame rendered by a TestDriver.
+type TestFrame struct {
+	// Time is the time of the fake clock when the frame was rendered.
+	Time time.Time
+
+	// Output is the rendered output.
+	Output string
+}
+
+// TestEvent is a mount or unmount event recorded by a TestDriver.
+type TestEvent struct {
+	// Time is the time of the fake clock when the event occurred.
+	Time time.Time
+
+	// Component is the component that was mounted or unmounted.
+	Component Component
+
+	// Mounted is true for a mount event and false for an unmount event.
+	Mounted bool
+}
+
+// NewTestDriver creates a TestDriver for a new document with the given
+// components. No frames are rendered until Step is called.
+func NewTestDriver(t testing.T, c ...Component) *TestDriver {
+	driver := &TestDriver{
+		Renderer: &StringRenderer{},
+		Clock:    NewTestClock(time.Time{}),
+		t:        t,
+	}
+
+	d := New()
+	d.SetRenderer(&testDriverRenderer{driver: driver})
+	d.SetClock(driver.Clock)
+	d.Append(c...)
+	d.mountHook = func(c ComponentMounter, mounted bool) {
+		driver.Events = append(driver.Events, TestEvent{
+			Time:      driver.Clock.Now(),
+			Component: c,
+			Mounted:   mounted,
+		})
+	}
+	driver.Document = d
+
+	return driver
+}
+
+// Step advances the clock by the given duration and then renders a single
+// frame. This returns the output of the frame. If the frame was skipped
+// because nothing changed (see ComponentDirtier), this returns the output
+// of the previous frame and nothing new is recorded. If nothing was
+// rendered, such as when all components are removed, this returns an
+// empty string.
+func (d *TestDriver) Step(dur time.Duration) string {
+	d.t.Helper()
+
+	d.Clock.Advance(dur)
+	frames := len(d.Frames)
+	skipped := d.Document.renderFrame()
+	if skipped && frames > 0 {
+		frames--
+	}
+	if len(d.Frames) == frames {
+		return ""
+	}
+
+	return d.Frames[len(d.Frames)-1].Output
+}
+
+// Finalize finalizes all the components currently in the document. The
+// next Step will render them a final time and then they are removed.
+func (d *TestDriver) Finalize() {
+	d.Document.mu.Lock()
+	defer d.Document.mu.Unlock()
+	d.Document.finalize()
+}
+
+// Input parses s as terminal input and gives the resulting key and mouse
+// events to the components rendered in the last frame, the same as input
+// read after Document.EnableInput. Incomplete escape sequences at the end of s are
+// flushed, so a trailing "\x1b" is the escape key.
+func (d *TestDriver) Input(s string) {
+	var p inputParser
+	events := p.Feed([]byte(s))
+	events = append(events, p.Flush()...)
+	d.Document.dispatchEvents(events)
+}
+
+// Close closes the document. Any frames rendered by closing are recorded.
+func (d *TestDriver) Close() {
+	d.t.Helper()
+
+	if err := d.Document.Close(); err != nil {
+		d.t.Fatalf("error closing document: %s", err)
+	}
+}
+
+// Outputs returns the output of every frame rendered so far.
+func (d *TestDriver) Outputs() []string {
+	result := make([]string, len(d.Frames))
+	for i, f := range d.Frames {
+		result[i] = f.Output
+	}
+
+	return result
+}
+
+// testDriverRenderer records every frame rendered for a TestDriver.
+type testDriverRenderer struct {
+	driver *TestDriver
+}
+
+func (r *testDriverRenderer) LayoutRoot() *flex.Node {
+	return r.driver.Renderer.LayoutRoot()
+}
+
+func (r *testDriverRenderer) RenderRoot(root, prev *flex.Node) {
+	r.driver.Renderer.RenderRoot(root, prev)
+	r.driver.Frames = append(r.driver.Frames, TestFrame{
+		Time:   r.driver.Clock.Now(),
+		Output: r.driver.Renderer.Builder.String(),
+	})
+}
+
+// TestClock is a fake Clock for tests. The time only changes when
+// Advance is called.
+type TestClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []testClockWaiter
+}
+
+type testClockWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+// NewTestClock returns a TestClock set to the given time. If the time is
+// zero, an arbitrary fixed time is used so that output is reproducible.
+func NewTestClock(now time.Time) *TestClock {
+	if now.IsZero() {
+		now = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	return &TestClock{now: now}
+}
+
+// Now returns the current time of the clock.
+func (c *TestClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After returns a channel that receives the time once the clock has been
+// advanced by at least the given duration.
+func (c *TestClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+
+	c.waiters = append(c.waiters, testClockWaiter{at: c.now.Add(d), ch: ch})
+	return ch
+}
+
+// Advance moves the clock forward by the given duration. Any channels
+// returned by After that are due are sent the new time.
+func (c *TestClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+
+	waiters := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.at.After(c.now) {
+			waiters = append(waiters, w)
+			continue
+		}
+
+		w.ch <- c.now
+	}
+	c.waiters = waiters
+}
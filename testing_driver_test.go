@@ -0,0 +1,84 @@
+package glint
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTestDriver(t *testing.T) {
+	require := require.New(t)
+
+	var mount testMount
+	frame := 0
+	d := NewTestDriver(t, &mount, TextFunc(func(rows, cols uint) string {
+		frame++
+		return "frame " + string(rune('0'+frame))
+	}))
+
+	require.Equal("frame 1", d.Step(time.Second))
+	require.Equal("frame 2", d.Step(time.Second))
+	require.Len(d.Frames, 2)
+	require.Equal(time.Second, d.Frames[1].Time.Sub(d.Frames[0].Time))
+
+	require.Len(d.Events, 1)
+	require.True(d.Events[0].Mounted)
+	require.Equal(&mount, d.Events[0].Component)
+
+	d.Close()
+	require.Equal([]string{"frame 1", "frame 2", "frame 3"}, d.Outputs())
+	require.Len(d.Events, 2)
+	require.False(d.Events[1].Mounted)
+	require.Equal(uint32(1), mount.unmount)
+}
+
+func TestTestDriver_finalize(t *testing.T) {
+	require := require.New(t)
+
+	d := NewTestDriver(t, Text("hello"))
+	require.Equal("hello", d.Step(0))
+
+	d.Finalize()
+	d.Document.Append(Text("world"))
+	require.Equal("hello\nworld", d.Step(0))
+	require.Equal("world", d.Step(0))
+}
+
+func TestTestClock_after(t *testing.T) {
+	require := require.New(t)
+
+	c := NewTestClock(time.Time{})
+	ch := c.After(time.Second)
+
+	c.Advance(500 * time.Millisecond)
+	select {
+	case <-ch:
+		t.Fatal("should not fire")
+	default:
+	}
+
+	c.Advance(500 * time.Millisecond)
+	select {
+	case v := <-ch:
+		require.Equal(c.Now(), v)
+	default:
+		t.Fatal("should fire")
+	}
+}
+
+func TestDocument_clockContext(t *testing.T) {
+	require := require.New(t)
+
+	d := NewTestDriver(t, &testClockComponent{})
+	start := d.Clock.Now()
+	require.Equal(start.Format(time.RFC3339), d.Step(0))
+	require.Equal(start.Add(time.Hour).Format(time.RFC3339), d.Step(time.Hour))
+}
+
+type testClockComponent struct{}
+
+func (c *testClockComponent) Body(ctx context.Context) Component {
+	return Text(ClockFromContext(ctx).Now().Format(time.RFC3339))
+}
@@ -0,0 +1,210 @@
+package glint
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/mitchellh/go-testing-interface"
+
+	"github.com/mitchellh/go-glint/flex"
+)
+
+// TestRenderGolden renders the component using the string renderer and
+// compares the output to a golden file in the "testdata" directory. This is
+// a test helper function for writing components.
+//
+// If no widths are given, the component is rendered at the default width of
+// the StringRenderer and compared to "testdata/<name>.golden". Otherwise,
+// the component is rendered once for each width and compared to
+// "testdata/<name>.w<width>.golden".
+//
+// The golden output includes markers for styled text, such as
+// "⟨fg=red bold⟩text⟨/⟩", so that styles are tested as well. On mismatch,
+// a line diff is shown with whitespace made visible.
+//
+// To write the golden files, run the tests with the "-update" flag or with
+// the GLINT_UPDATE_GOLDEN environment variable set to "1". The flag must be
+// defined by the test package, for example:
+//
+//	var _ = flag.Bool("update", false, "update golden files")
+func TestRenderGolden(t testing.T, name string, c Component, widths ...uint) {
+	t.Helper()
+
+	if len(widths) == 0 {
+		testRenderGolden(t, filepath.Join("testdata", name+".golden"), c, 0)
+		return
+	}
+
+	for _, width := range widths {
+		path := filepath.Join("testdata", fmt.Sprintf("%s.w%d.golden", name, width))
+		testRenderGolden(t, path, c, width)
+	}
+}
+
+func testRenderGolden(t testing.T, path string, c Component, width uint) {
+	t.Helper()
+
+	// Render
+	r := &goldenRenderer{StringRenderer: StringRenderer{Width: width}}
+	d := New()
+	d.SetRenderer(r)
+	d.Append(c)
+	d.RenderFrame()
+	actual := r.Builder.String()
+
+	if goldenUpdate() {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("error creating golden directory: %s", err)
+		}
+		if err := ioutil.WriteFile(path, []byte(actual), 0644); err != nil {
+			t.Fatalf("error writing golden file: %s", err)
+		}
+
+		return
+	}
+
+	expected, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("error reading golden file (run with -update to create it): %s", err)
+		return
+	}
+
+	if string(expected) != actual {
+		t.Errorf("output does not match golden file %s (run with -update to update it)\n\n%s",
+			path, goldenDiff(string(expected), actual))
+	}
+}
+
+// goldenRenderer is a StringRenderer that renders style markers.
+type goldenRenderer struct {
+	StringRenderer
+}
+
+func (r *goldenRenderer) RenderRoot(root, prev *flex.Node) {
+	if r.Builder == nil {
+		r.Builder = &strings.Builder{}
+	}
+
+	r.Builder.Reset()
+	r.renderTree(r.Builder, root, goldenStyle)
+}
+
+// goldenStyle wraps each line of v in markers describing the style.
+func goldenStyle(s TextStyle, v string) string {
+	var attrs []string
+	if s.Foreground.Set {
+		attrs = append(attrs, "fg="+goldenColor(s.Foreground))
+	}
+	if s.Background.Set {
+		attrs = append(attrs, "bg="+goldenColor(s.Background))
+	}
+	if s.Bold {
+		attrs = append(attrs, "bold")
+	}
+	if s.Italic {
+		attrs = append(attrs, "italic")
+	}
+	if s.Underline {
+		attrs = append(attrs, "underline")
+	}
+	if len(attrs) == 0 {
+		return v
+	}
+
+	start := "⟨" + strings.Join(attrs, " ") + "⟩"
+	lines := strings.Split(v, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = start + line + "⟨/⟩"
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func goldenColor(c StyleColor) string {
+	if c.Name != "" {
+		return c.Name
+	}
+
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// goldenDiff returns a line diff between expected and actual with
+// whitespace made visible.
+func goldenDiff(expected, actual string) string {
+	a := strings.Split(expected, "\n")
+	b := strings.Split(actual, "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of
+	// a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var out strings.Builder
+	out.WriteString("--- expected\n+++ actual\n")
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			out.WriteString("  " + goldenVisible(a[i]) + "\n")
+			i++
+			j++
+
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			out.WriteString("- " + goldenVisible(a[i]) + "\n")
+			i++
+
+		default:
+			out.WriteString("+ " + goldenVisible(b[j]) + "\n")
+			j++
+		}
+	}
+
+	return out.String()
+}
+
+// goldenVisible makes whitespace in a line visible.
+func goldenVisible(v string) string {
+	return strings.NewReplacer(" ", "·", "\t", "→", "\r", "␍").Replace(v) + "⏎"
+}
+
+// goldenUpdate returns true if golden files should be updated. We don't
+// register a flag ourselves so that we don't add flags to every program
+// using this library.
+func goldenUpdate() bool {
+	if v, err := strconv.ParseBool(os.Getenv(goldenUpdateEnv)); err == nil {
+		return v
+	}
+
+	f := flag.Lookup(goldenUpdateFlag)
+	if f == nil {
+		return false
+	}
+
+	v, _ := strconv.ParseBool(f.Value.String())
+	return v
+}
+
+const (
+	goldenUpdateEnv  = "GLINT_UPDATE_GOLDEN"
+	goldenUpdateFlag = "update"
+)
@@ -0,0 +1,69 @@
+package glint
+
+import (
+	"flag"
+	"os"
+	"strings"
+	"testing"
+
+	gotesting "github.com/mitchellh/go-testing-interface"
+	"github.com/stretchr/testify/require"
+)
+
+var _ = flag.Bool("update", false, "update golden files")
+
+func TestTestRenderGolden(t *testing.T) {
+	TestRenderGolden(t, "golden_styled", Layout(
+		Style(Text("ok"), Color("green"), Bold()),
+		Layout(Style(Text("hello world"), BGColorRGB(1, 2, 3))).MarginLeft(1),
+	).Row(), 6, 20)
+}
+
+func TestTestRenderGolden_mismatch(t *testing.T) {
+	if goldenUpdate() {
+		t.Skip("updating golden files")
+	}
+
+	require := require.New(t)
+
+	rt := &gotesting.RuntimeT{}
+	TestRenderGolden(rt, "golden_styled", Text("nope"), 20)
+	require.True(rt.Failed())
+}
+
+func TestGoldenUpdate(t *testing.T) {
+	require := require.New(t)
+
+	// We don't register any flags of our own.
+	require.Nil(flag.Lookup("glint.update"))
+
+	// The environment variable takes precedence over the flag.
+	old, ok := os.LookupEnv(goldenUpdateEnv)
+	defer func() {
+		if ok {
+			os.Setenv(goldenUpdateEnv, old)
+		} else {
+			os.Unsetenv(goldenUpdateEnv)
+		}
+	}()
+	os.Setenv(goldenUpdateEnv, "1")
+	require.True(goldenUpdate())
+	os.Setenv(goldenUpdateEnv, "0")
+	require.False(goldenUpdate())
+}
+
+func TestGoldenDiff(t *testing.T) {
+	require := require.New(t)
+
+	actual := goldenDiff("one\ntwo three\nfour", "one\ntwo  three\nfour\n\tfive")
+	require.Equal(strings.Join([]string{
+		"--- expected",
+		"+++ actual",
+		"  one⏎",
+		"- two·three⏎",
+		"+ two··three⏎",
+		"  four⏎",
+		"+ →five⏎",
+		"",
+	}, "\n"), actual)
+}
@@ -8,12 +8,20 @@ import (
 type TextComponent struct {
 	terminalComponent
 	f func(rows, cols uint) string
+
+	// static is true if f always returns text. This is set for Text so
+	// that we don't need to measure static text again if it is unchanged.
+	static bool
+	text   string
 }
 
 // Text creates a TextComponent for static text. The text here will be word
 // wrapped automatically based on the width of the terminal.
 func Text(v string) *TextComponent {
-	return TextFunc(func(rows, cols uint) string { return v })
+	c := TextFunc(func(rows, cols uint) string { return v })
+	c.static = true
+	c.text = v
+	return c
 }
 
 // TextFunc creates a TextComponent for text that is dependent on the
@@ -28,6 +36,13 @@ func (el *TextComponent) Body(context.Context) Component {
 	return nil
 }
 
+// Dirty implements ComponentDirtier. Static text created with Text is
+// never dirty. Text created with TextFunc is always dirty since the function
+// may depend on any state.
+func (el *TextComponent) Dirty(context.Context) bool {
+	return !el.static
+}
+
 func (el *TextComponent) Render(rows, cols uint) string {
 	if el.f == nil {
 		return ""
@@ -35,3 +50,8 @@ func (el *TextComponent) Render(rows, cols uint) string {
 
 	return el.f(rows, cols)
 }
+
+// sameText returns true if c2 is known to render the same text as c.
+func (el *TextComponent) sameText(c2 *TextComponent) bool {
+	return el.static && c2.static && el.text == c2.text
+}
@@ -2,51 +2,94 @@ package glint
 
 import (
 	"context"
+	"reflect"
 
 	"github.com/mitchellh/go-glint/flex"
 )
 
+// tree builds the children of parent from the component c.
+//
+// prev are the children of the same parent from the previous render, if
+// any. Nodes in prev are reconciled with the new components: a node is
+// reused if its component has the same type and either the same key (see
+// Key) or, for components without a key, the same position. Reused nodes
+// keep their cached layout and are only marked dirty if their style or
+// measure inputs changed, so unchanged parts of the tree aren't laid out
+// again. Entries in prev may be nil for positions without a previous node.
 func tree(
 	ctx context.Context,
 	parent *flex.Node,
+	prev []*flex.Node,
 	c Component,
 	finalize bool,
+) {
+	b := &treeBuilder{parent: parent, prev: prev}
+	for _, node := range prev {
+		if node == nil {
+			continue
+		}
+
+		if key := nodeKey(node); key != nil {
+			if b.keyed == nil {
+				b.keyed = map[interface{}]*flex.Node{}
+			}
+
+			b.keyed[key] = node
+		}
+	}
+
+	b.build(ctx, c, finalize, nil)
+	b.attach()
+}
+
+// treeBuilder builds the list of children for a single parent node.
+type treeBuilder struct {
+	parent   *flex.Node
+	prev     []*flex.Node
+	keyed    map[interface{}]*flex.Node
+	children []*flex.Node
+}
+
+func (b *treeBuilder) build(
+	ctx context.Context,
+	c Component,
+	finalize bool,
+	key interface{},
 ) {
 	// Don't do anything with no component
 	if c == nil {
 		return
 	}
 
-	// Fragments don't create a node
+	// Fragments, contexts, and keys don't create a node
 	switch c := c.(type) {
 	case *contextComponent:
 		for i := 0; i < len(c.pairs); i += 2 {
 			ctx = context.WithValue(ctx, c.pairs[i], c.pairs[i+1])
 		}
 
-		tree(ctx, parent, c.inner, finalize)
+		b.build(ctx, c.inner, finalize, key)
 		return
 
 	case *fragmentComponent:
 		for _, c := range c.List {
-			tree(ctx, parent, c, finalize)
+			b.build(ctx, c, finalize, nil)
 		}
 
 		return
-	}
 
-	// Setup our node
-	node := flex.NewNodeWithConfig(parent.Config)
-	parent.InsertChild(node, len(parent.Children))
-
-	// Setup our default context
-	parentCtx := &parentContext{C: c}
-	node.Context = parentCtx
+	case *keyedComponent:
+		b.build(ctx, c.inner, finalize, c.key)
+		return
+	}
 
-	// Check if we're finalized and note it
-	if _, ok := c.(*finalizedComponent); ok {
-		parentCtx.Finalized = true
+	// Setup our node, reusing the previous node if we can.
+	node := b.match(c, key)
+	reused := node != nil
+	if !reused {
+		node = flex.NewNodeWithConfig(b.parent.Config)
 	}
+	b.children = append(b.children, node)
 
 	// Finalize
 	if finalize {
@@ -55,29 +98,187 @@ func tree(
 		}
 	}
 
-	// Setup a custom layout
+	// Build the style on a scratch node and copy it over. This only marks
+	// the node dirty if the style actually changed.
+	style := flex.NewNodeWithConfig(b.parent.Config)
 	if c, ok := c.(componentLayout); ok {
-		c.Layout().Apply(node)
+		c.Layout().Apply(style)
 	}
 
 	switch c := c.(type) {
 	case *TextComponent:
-		node.Context = &TextNodeContext{C: c, Context: ctx}
-		node.StyleSetFlexShrink(1)
-		node.StyleSetFlexGrow(0)
-		node.StyleSetFlexDirection(flex.FlexDirectionRow)
-		node.SetMeasureFunc(MeasureTextNode)
+		style.StyleSetFlexShrink(1)
+		style.StyleSetFlexGrow(0)
+		style.StyleSetFlexDirection(flex.FlexDirectionRow)
+		flex.NodeCopyStyle(node, style)
+
+		tctx, _ := node.Context.(*TextNodeContext)
+		if tctx == nil {
+			tctx = &TextNodeContext{}
+			node.Context = tctx
+			node.SetMeasureFunc(MeasureTextNode)
+		}
+
+		// If the text may have changed then we have to measure again.
+		if reused && !tctx.C.sameText(c) {
+			node.MarkDirty()
+		}
+
+		tctx.C = c
+		tctx.Context = ctx
+		tctx.Style = styleFromContext(ctx)
+		tctx.key = key
+		tctx.measured = false
 
 	default:
+		flex.NodeCopyStyle(node, style)
+
+		pctx, _ := node.Context.(*parentContext)
+		if pctx == nil {
+			pctx = &parentContext{}
+			node.Context = pctx
+		}
+
+		pctx.C = c
+		pctx.key = key
+
+		// Check if we're finalized and note it
+		_, pctx.Finalized = c.(*finalizedComponent)
+
+		// Boxes draw a border, so we note the style to draw it with.
+		if box, ok := c.(*BoxComponent); ok {
+			pctx.style = box.borderStyle(ctx)
+		}
+
+		// The hooks and focus for this node are only available to this Body
+		// call, not to the children.
+		pctx.hooks.idx = 0
+		bodyCtx := context.WithValue(ctx, hooksCtxKey, &pctx.hooks)
+		if focused, ok := ctx.Value(focusedCtxKey).(focusedValue); ok && focused.isFocused(pctx, c) {
+			bodyCtx = context.WithValue(bodyCtx, isFocusedCtxKey, true)
+		}
+
 		// If this is not terminal then we nest.
-		tree(ctx, node, c.Body(ctx), finalize)
+		tree(ctx, node, node.Children, c.Body(bodyCtx), finalize)
 	}
+}
 
+// match returns the previous node to reuse for the component c with the
+// given key, or nil if there is no match.
+func (b *treeBuilder) match(c Component, key interface{}) *flex.Node {
+	var node *flex.Node
+	if key != nil {
+		node = b.keyed[key]
+		delete(b.keyed, key)
+	} else if idx := len(b.children); idx < len(b.prev) && b.prev[idx] != nil && nodeKey(b.prev[idx]) == nil {
+		node = b.prev[idx]
+	}
+	if node == nil {
+		return nil
+	}
+
+	tctx, ok := node.Context.(treeContext)
+	if !ok || reflect.TypeOf(tctx.Component()) != reflect.TypeOf(c) {
+		return nil
+	}
+
+	return node
+}
+
+// attach sets the built children on the parent. The parent is only marked
+// dirty if the children changed.
+func (b *treeBuilder) attach() {
+	parent := b.parent
+	changed := len(parent.Children) != len(b.children)
+	for i := 0; !changed && i < len(b.children); i++ {
+		changed = parent.Children[i] != b.children[i]
+	}
+	if !changed {
+		return
+	}
+
+	// Detach all the old children. We don't use RemoveChild since that
+	// resets the layout and we want to keep the cached layout for any
+	// reused nodes.
+	for _, child := range b.prev {
+		if child != nil {
+			child.Parent = nil
+		}
+	}
+	for _, child := range b.children {
+		child.Parent = nil
+	}
+
+	parent.Children = nil
+	for i, child := range b.children {
+		parent.InsertChild(child, i)
+	}
+}
+
+// treeLayout must be called after the layout is calculated for a tree
+// built with tree. Reused text nodes that weren't measured again may have
+// been laid out using an older cached measurement than the text that we
+// have, so if their layout changed we render the text again to fit.
+func treeLayout(parent *flex.Node) {
+	for _, child := range parent.Children {
+		tctx, ok := child.Context.(*TextNodeContext)
+		if !ok {
+			treeLayout(child)
+			continue
+		}
+
+		size := flex.Size{
+			Width:  child.LayoutGetWidth(),
+			Height: child.LayoutGetHeight(),
+		}
+		if !tctx.measured && size != tctx.layout {
+			child.Measure(child,
+				size.Width, flex.MeasureModeAtMost,
+				size.Height, flex.MeasureModeAtMost,
+			)
+		}
+
+		tctx.layout = size
+	}
+}
+
+// treeSnapshot returns a copy of the tree rooted at node with the layout
+// as it is now. Nodes are reused across frames, so this is used to keep the
+// previous layout around after the next frame is built. The node contexts
+// are shared with the original tree.
+func treeSnapshot(node *flex.Node) *flex.Node {
+	result := *node
+	result.Parent = nil
+	result.Children = make([]*flex.Node, len(node.Children))
+	for i, child := range node.Children {
+		result.Children[i] = treeSnapshot(child)
+		result.Children[i].Parent = &result
+	}
+
+	return &result
+}
+
+// nodeKey returns the key of the component for the node, if any.
+func nodeKey(node *flex.Node) interface{} {
+	switch ctx := node.Context.(type) {
+	case *parentContext:
+		return ctx.key
+	case *TextNodeContext:
+		return ctx.key
+	default:
+		return nil
+	}
 }
 
 type parentContext struct {
 	C         Component
 	Finalized bool
+
+	key   interface{}
+	hooks componentHooks
+
+	// style is the style to draw a border with for a BoxComponent.
+	style TextStyle
 }
 
 func (c *parentContext) Component() Component { return c.C }
@@ -0,0 +1,110 @@
+package glint
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mitchellh/go-glint/flex"
+)
+
+func TestTree_reusePosition(t *testing.T) {
+	require := require.New(t)
+
+	root1 := testTree(nil, Text("hello"), TextFunc(func(rows, cols uint) string { return "dyn" }))
+	root2 := testTree(root1.Children, Text("hello"), TextFunc(func(rows, cols uint) string { return "dyn" }))
+	require.Len(root2.Children, 2)
+	require.Equal(root1.Children, root2.Children)
+
+	// Static text that didn't change doesn't need to be measured again
+	// but dynamic text does.
+	require.False(root2.Children[0].IsDirty)
+	require.True(root2.Children[1].IsDirty)
+
+	// Changed static text is measured again
+	root3 := testTree(root2.Children, Text("world"))
+	require.Len(root3.Children, 1)
+	require.Equal(root2.Children[0], root3.Children[0])
+	require.True(root3.Children[0].IsDirty)
+}
+
+func TestTree_reuseType(t *testing.T) {
+	require := require.New(t)
+
+	root1 := testTree(nil, Text("hello"))
+	root2 := testTree(root1.Children, Layout(Text("hello")))
+	require.Len(root2.Children, 1)
+	require.NotEqual(root1.Children[0], root2.Children[0])
+}
+
+func TestTree_reuseKey(t *testing.T) {
+	require := require.New(t)
+
+	root1 := testTree(nil, Key("a", Text("a")), Key("b", Text("b")))
+	a, b := root1.Children[0], root1.Children[1]
+
+	// Reordered
+	root2 := testTree(root1.Children, Key("b", Text("b")), Key("a", Text("a")))
+	require.Equal([]*flex.Node{b, a}, root2.Children)
+	require.False(a.IsDirty)
+	require.False(b.IsDirty)
+
+	// Inserted at the front
+	root3 := testTree(root2.Children, Text("c"), Key("b", Text("b")), Key("a", Text("a")))
+	require.Len(root3.Children, 3)
+	require.Equal([]*flex.Node{b, a}, root3.Children[1:])
+
+	// Keyed nodes don't match unkeyed nodes
+	root4 := testTree(root3.Children, Text("a"))
+	require.NotEqual(a, root4.Children[0])
+}
+
+func TestTree_reuseNested(t *testing.T) {
+	require := require.New(t)
+
+	c := &testComponent{Text("hello")}
+	root1 := testTree(nil, c)
+	root2 := testTree(root1.Children, c)
+	require.Equal(root1.Children[0], root2.Children[0])
+	require.Equal(root1.Children[0].Children, root2.Children[0].Children)
+	require.False(root2.Children[0].IsDirty)
+}
+
+func TestTree_reuseLayout(t *testing.T) {
+	require := require.New(t)
+
+	d := NewTestDriver(t, Text("hello world"), TextFunc(func(rows, cols uint) string {
+		return "x"
+	}))
+
+	require.Equal("hello world\nx", d.Step(0))
+	d.Renderer.Width = 5
+	require.Equal("hello\nworld\nx", d.Step(0))
+	d.Renderer.Width = 80
+	require.Equal("hello world\nx", d.Step(0))
+	d.Renderer.Width = 5
+	require.Equal("hello\nworld\nx", d.Step(0))
+}
+
+// testTree builds a tree with the given previous children and calculates
+// the layout. This returns the root before layout so that dirty flags can
+// be checked.
+func testTree(prev []*flex.Node, c ...Component) *flex.Node {
+	if len(prev) > 0 {
+		root := prev[0].Parent
+		flex.CalculateLayout(root, flex.Undefined, flex.Undefined, flex.DirectionLTR)
+		treeLayout(root)
+	}
+
+	root := flex.NewNode()
+	root.StyleSetWidth(80)
+	tree(context.Background(), root, prev, Fragment(c...), false)
+	return root
+}
+
+type testComponent struct {
+	inner Component
+}
+
+func (c *testComponent) Body(context.Context) Component { return c.inner }